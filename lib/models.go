@@ -65,10 +65,26 @@ type QueryOptions struct {
 	EndOffset        *int    `json:"endOffset,omitempty"`
 	ResultObject     *string `json:"resultObject,omitempty"`
 	ResultKey        *int    `json:"resultKey,omitempty"`
+	// ColumnNames overrides the keys of the columns, if ResultObject is "object".
+	ColumnNames []string `json:"columnNames,omitempty"`
+	// TimeLayout and TimeLocation format the timestamps, if ResultObject is "object". Defaults to RFC 3339 in UTC.
+	TimeLayout   *string `json:"timeLayout,omitempty"`
+	TimeLocation *string `json:"timeLocation,omitempty"`
+	// Compare additionally queries a shifted window and returns {current, previous, delta, deltaPercent}.
+	// Supported values are "previousPeriod", "previousWeek", "previousMonth" and "previousYear".
+	Compare *string `json:"compare,omitempty"`
 }
 
 type DeviceQuery struct {
+	// Last selects the window ending now (or at End), e.g. "7d".
 	Last *string `json:"last,omitempty"`
+	// Start and End select an absolute window as RFC 3339 timestamps. End defaults to now.
+	Start *string `json:"start,omitempty"`
+	End   *string `json:"end,omitempty"`
+	// Period selects the previous calendar period: "lastDay", "lastWeek", "lastMonth" or "lastYear".
+	Period *string `json:"period,omitempty"`
+	// Summary wraps the device list as {"devices": [...], "availability": {...}} with the aggregated statistics.
+	Summary bool `json:"summary,omitempty"`
 }
 
 type Report struct {
@@ -81,19 +97,388 @@ type Report struct {
 	ReportFiles    []ReportFile            `json:"reportFiles,omitempty"`
 	Cron           string                  `json:"cron,omitempty"`
 	ScheduledFor   *time.Time              `json:"-"` // internal use
+	TriggeredAt    *time.Time              `json:"-"` // internal use, time of the latest triggered run
 	EmailReceivers []string                `json:"emailReceivers"`
-	EmailSubject   string                  `json:"emailSubject,omitempty"`
+	EmailSubject   string                  `json:"emailSubject,omitempty"` // EmailSubject, EmailText and EmailHTML are templates, see README
 	EmailText      string                  `json:"emailText,omitempty"`
 	EmailHTML      string                  `json:"emailHTML,omitempty"`
+	EmailZip       bool                    `json:"emailZip,omitempty"` // attach the report file as zip archive
 	CreatedAt      time.Time               `json:"createdAt,omitempty"`
 	UpdatedAt      time.Time               `json:"updatedAt,omitempty"`
+	Permissions    Permissions             `json:"permissions"`
+	Shared         bool                    `bson:"-" json:"shared"` // true if the report is owned by another user
+	Version        int                     `json:"version,omitempty"`
+	Revision       int                     `json:"revision"` // increased on every update, updates have to provide the current revision
+	LastRun        *RunStatus              `json:"lastRun,omitempty"`
+	Paused         bool                    `json:"paused,omitempty"` // paused reports are not scheduled
+	Webhooks       []Webhook               `json:"webhooks,omitempty"`
+	Targets        []DeliveryTarget        `json:"targets,omitempty"`
+	Recipients     []Recipient             `json:"recipients,omitempty"` // in addition to EmailReceivers, which are sent as Bcc
+	ReplyTo        *EmailAddress           `json:"replyTo,omitempty"`
+	Conditions     *DeliveryConditions     `json:"conditions,omitempty"` // decide, whether created files are delivered
+	Outputs        []ReportOutput          `json:"outputs,omitempty"`    // additional files created from the same data
+	Triggers       []Trigger               `json:"triggers,omitempty"`   // events, which run the report in addition to Cron
+	Debounce       string                  `json:"debounce,omitempty"`   // minimal interval between triggered runs, e.g. "1h"
+}
+
+const (
+	TriggerTypeKafka         = "kafka"
+	TriggerTypeDeviceOffline = "deviceOffline"
+)
+
+// Trigger runs a report on events. Kafka triggers match messages of Topic, which fulfill all Filter rules.
+// DeviceOffline triggers match disconnects of the DeviceIds in the device log, which have to be readable by the report owner.
+type Trigger struct {
+	Id        string          `json:"id"`
+	Type      string          `json:"type"`
+	Topic     string          `json:"topic,omitempty"`
+	Filter    []ConditionRule `json:"filter,omitempty"`
+	DeviceIds []string        `json:"deviceIds,omitempty"`
+}
+
+// ReportOutput renders the data of a report with another template, e.g. as XLSX next to the PDF of TemplateName.
+type ReportOutput struct {
+	TemplateName string `json:"templateName"`
+}
+
+const (
+	RecipientRoleTo  = "to"
+	RecipientRoleCc  = "cc"
+	RecipientRoleBcc = "bcc"
+)
+
+type EmailAddress struct {
+	Name  string `json:"name,omitempty"`
+	Email string `json:"email"`
+}
+
+// Recipient receives the emails of a report. Role is one of to, cc or bcc and defaults to to.
+type Recipient struct {
+	Name   string `json:"name,omitempty"`
+	Email  string `json:"email"`
+	Role   string `json:"role,omitempty"`
+	Locale string `json:"locale,omitempty"` // e.g. "de" or "en-US"
+}
+
+const (
+	ConditionMatchAny = "any"
+	ConditionMatchAll = "all"
+
+	ConditionActionDeliver  = "deliver"
+	ConditionActionSkip     = "skip"
+	ConditionActionRedirect = "redirect"
+)
+
+// DeliveryConditions are evaluated against the resolved report data of every created file.
+// If they are not met, the file is not delivered or, with ConditionActionRedirect, only emailed to RedirectTo.
+type DeliveryConditions struct {
+	Match      string          `json:"match,omitempty"` // ConditionMatchAny (default) or ConditionMatchAll rules have to match
+	Rules      []ConditionRule `json:"rules"`
+	Otherwise  string          `json:"otherwise,omitempty"` // ConditionActionSkip (default) or ConditionActionRedirect
+	RedirectTo []Recipient     `json:"redirectTo,omitempty"`
+}
+
+// ConditionRule compares the values at Path of the resolved report data with Value.
+// Path is dot separated, "*" selects all elements of a list or object, e.g. "devices.*.availability.availability_percent".
+// The rule matches, if any selected value matches.
+type ConditionRule struct {
+	Path     string      `json:"path"`
+	Operator string      `json:"operator"` // <, <=, >, >=, == or !=
+	Value    interface{} `json:"value"`
+}
+
+// ConditionOutcome is the result of the delivery conditions for a report file.
+type ConditionOutcome struct {
+	Met     bool     `json:"met"`
+	Action  string   `json:"action"`            // ConditionActionDeliver, ConditionActionSkip or ConditionActionRedirect
+	Matched []string `json:"matched,omitempty"` // descriptions of the matched rules
+}
+
+const (
+	TargetTypeS3         = "s3"
+	TargetTypeFilesystem = "filesystem"
+
+	// DefaultKeyTemplate is used by delivery targets without key template.
+	DefaultKeyTemplate = "{report}/{yyyy}/{mm}/{fileId}.{ext}"
+)
+
+// DeliveryTarget stores created report files in an S3-compatible bucket or a directory.
+// The key template supports the placeholders {report}, {reportName}, {fileId}, {ext}, {yyyy}, {mm}, {dd}, {HH} and {MM}.
+type DeliveryTarget struct {
+	Id          string            `json:"id"`
+	Type        string            `json:"type"`
+	KeyTemplate string            `json:"keyTemplate,omitempty"`
+	S3          *S3Target         `json:"s3,omitempty"`
+	Filesystem  *FilesystemTarget `json:"filesystem,omitempty"`
+}
+
+type S3Target struct {
+	Endpoint  string `json:"endpoint"`
+	Region    string `json:"region"`
+	Bucket    string `json:"bucket"`
+	AccessKey string `json:"accessKey"`
+	SecretKey string `json:"secretKey,omitempty"` // write-only, kept if empty on update
+	PathStyle bool   `json:"pathStyle"`
+}
+
+// FilesystemTarget is a directory relative to the configured delivery root.
+type FilesystemTarget struct {
+	Path string `json:"path"`
+}
+
+const (
+	WebhookModeNotification = "notification"
+	WebhookModeFile         = "file"
+)
+
+// Webhook receives created report files. In notification mode a JSON notification with a signed download link is
+// posted, in file mode the notification and the file are posted as multipart form. Requests are signed with Secret,
+// which is required for new webhooks. Secret is write-only, it is kept if empty on update and never returned.
+type Webhook struct {
+	Id     string `json:"id"`
+	Url    string `json:"url"`
+	Mode   string `json:"mode"`
+	Secret string `json:"secret,omitempty"`
+}
+
+// WebhookNotification is the payload posted to webhooks.
+type WebhookNotification struct {
+	Event       string     `json:"event"`
+	ReportId    string     `json:"reportId"`
+	ReportName  string     `json:"reportName"`
+	FileId      string     `json:"fileId"`
+	Type        string     `json:"type"`
+	CreatedAt   time.Time  `json:"createdAt"`
+	RunId       string     `json:"runId,omitempty"` // groups the files of a run
+	DownloadUrl string     `json:"downloadUrl,omitempty"`
+	ExpiresAt   *time.Time `json:"expiresAt,omitempty"`
+}
+
+const (
+	DeliveryChannelEmail      = "email"
+	DeliveryChannelWebhook    = "webhook"
+	DeliveryChannelS3         = "s3"
+	DeliveryChannelFilesystem = "filesystem"
+)
+
+// ReportRun records the deliveries of a report file.
+type ReportRun struct {
+	Id         string            `bson:"_id" json:"id"`
+	ReportId   string            `json:"reportId"`
+	FileId     string            `json:"fileId"`
+	CreatedAt  time.Time         `json:"createdAt"`
+	Deliveries []Delivery        `json:"deliveries"`
+	Condition  *ConditionOutcome `json:"condition,omitempty"`
+}
+
+// Delivery is the outcome of delivering a report file to a single target.
+type Delivery struct {
+	Channel    string    `json:"channel"`
+	Target     string    `json:"target"`
+	Status     string    `json:"status"` // RunStatusSuccess or RunStatusFailed
+	Attempts   int       `json:"attempts"`
+	StatusCode int       `json:"statusCode,omitempty"`
+	Error      string    `json:"error,omitempty"`
+	Time       time.Time `json:"time"`
+	Mode       string    `json:"mode,omitempty"`   // how an email carried the files: AttachmentModeFile, AttachmentModeZip or AttachmentModeLink, joined by comma if they differ
+	FileId     string    `json:"fileId,omitempty"` // delivered file, if the run created several files
+}
+
+const (
+	AttachmentModeFile = "attachment"
+	AttachmentModeZip  = "zip"
+	AttachmentModeLink = "link"
+)
+
+const (
+	RunStatusSuccess = "success"
+	RunStatusFailed  = "failed"
+)
+
+// RunStatus is the outcome of the latest run of a report.
+type RunStatus struct {
+	Status string    `json:"status"`
+	Error  string    `json:"error,omitempty"`
+	Time   time.Time `json:"time"`
+}
+
+// CloneRequest describes how a report is cloned. Unset overrides keep the values of the cloned report.
+type CloneRequest struct {
+	Name           *string   `json:"name,omitempty"`
+	Cron           *string   `json:"cron,omitempty"`
+	EmailReceivers *[]string `json:"emailReceivers,omitempty"`
+	EmailSubject   *string   `json:"emailSubject,omitempty"`
+	EmailText      *string   `json:"emailText,omitempty"`
+	EmailHTML      *string   `json:"emailHTML,omitempty"`
+	// DeviceIds and ServiceIds replace ids in all queries of the report, mapping old ids to new ids.
+	DeviceIds  map[string]string `json:"deviceIds,omitempty"`
+	ServiceIds map[string]string `json:"serviceIds,omitempty"`
+}
+
+const ReportBundleFormatVersion = 1
+
+// ReportBundle is a portable report definition, which can be imported by other users and tenants.
+type ReportBundle struct {
+	FormatVersion int    `json:"formatVersion"`
+	Driver        string `json:"driver"`
+	TemplateName  string `json:"templateName"`
+	// Report is the definition of the report without recipients, webhooks and targets.
+	// Device ids in queries and triggers are replaced by the refs of Devices.
+	Report  Report         `json:"report"`
+	Devices []BundleDevice `json:"devices"`
+}
+
+// BundleDevice is a symbolic reference to a device used by the queries of a report bundle.
+type BundleDevice struct {
+	Ref        string   `json:"ref"`
+	DeviceId   string   `json:"deviceId"` // id in the exporting tenant
+	ServiceIds []string `json:"serviceIds"`
+}
+
+// ImportRequest imports a report bundle. DeviceMapping maps the refs of the bundle to device ids of the importing user.
+type ImportRequest struct {
+	Bundle        ReportBundle      `json:"bundle"`
+	DeviceMapping map[string]string `json:"deviceMapping"`
+}
+
+// ImportResult is the report created by an import, or the report an import would create in dry-run mode.
+type ImportResult struct {
+	Report  Report                 `json:"report"`
+	Devices map[string]string      `json:"devices"` // ref to device id
+	Changes map[string]AuditChange `json:"changes"` // changes compared to the bundle
+	DryRun  bool                   `json:"dryRun"`
+}
+
+const (
+	BulkActionDelete         = "delete"
+	BulkActionPause          = "pause"
+	BulkActionResume         = "resume"
+	BulkActionRun            = "run"
+	BulkActionCron           = "cron"
+	BulkActionEmailReceivers = "emailReceivers"
+)
+
+// BulkRequest is a list of operations, which are executed one after the other.
+type BulkRequest struct {
+	Operations []BulkOperation `json:"operations"`
+}
+
+// BulkOperation applies an action to reports. Cron and EmailReceivers are the new values of the respective actions.
+type BulkOperation struct {
+	Action         string   `json:"action"`
+	ReportIds      []string `json:"reportIds"`
+	Cron           string   `json:"cron,omitempty"`
+	EmailReceivers []string `json:"emailReceivers,omitempty"`
+}
+
+// BulkResult is the outcome of an operation on a single report.
+type BulkResult struct {
+	Action   string `json:"action"`
+	ReportId string `json:"reportId"`
+	Success  bool   `json:"success"`
+	FileId   string `json:"fileId,omitempty"`
+	Error    string `json:"error,omitempty"`
+	Err      error  `json:"-"`
+}
+
+// ReportPage is a page of a report listing.
+type ReportPage struct {
+	Data   []Report `json:"data"`
+	Total  int64    `json:"total"`
+	Limit  int64    `json:"limit"`
+	Offset int64    `json:"offset"`
+}
+
+// ReportVersion is a saved revision of the definition of a report.
+type ReportVersion struct {
+	Id         string    `bson:"_id" json:"id"`
+	ReportId   string    `json:"reportId"`
+	Version    int       `json:"version"`
+	Definition Report    `json:"definition"` // report without files, owner and permissions
+	CreatedBy  string    `json:"createdBy"`
+	CreatedAt  time.Time `json:"createdAt"`
+}
+
+// Permissions grant access to a report to users and Keycloak groups other than the owner.
+// Administrate implies execute, execute implies read.
+type Permissions struct {
+	Users  []PermissionGrant `json:"users"`
+	Groups []PermissionGrant `json:"groups"`
+}
+
+type PermissionGrant struct {
+	Id           string `json:"id"`
+	Read         bool   `json:"read"`
+	Execute      bool   `json:"execute"`
+	Administrate bool   `json:"administrate"`
 }
 
 type ReportFile struct {
-	Id        string    `json:"id,omitempty"`
-	Link      string    `json:"-"`
-	Type      string    `json:"type,omitempty"`
-	CreatedAt time.Time `json:"createdAt,omitempty"`
+	Id            string    `json:"id,omitempty"`
+	Link          string    `json:"-"`
+	Type          string    `json:"type,omitempty"`
+	CreatedAt     time.Time `json:"createdAt,omitempty"`
+	ReportVersion int       `json:"reportVersion,omitempty"` // version of the report definition, which produced the file
+	// PeriodStart and PeriodEnd span the resolved time windows of all queries of the report.
+	PeriodStart *time.Time `json:"periodStart,omitempty"`
+	PeriodEnd   *time.Time `json:"periodEnd,omitempty"`
+	// Values are the resolved scalar values of the report data, which are available in email templates.
+	Values    map[string]interface{} `json:"values,omitempty"`
+	Condition *ConditionOutcome      `json:"condition,omitempty"` // outcome of the delivery conditions of the report
+	// RunId groups the files created by the same run, one per output of the report.
+	RunId        string `json:"runId,omitempty"`
+	TemplateName string `json:"templateName,omitempty"` // template of additional outputs
+}
+
+// FileShare is a revocable, expiring public download link of a report file.
+type FileShare struct {
+	Id             string     `bson:"_id" json:"id"`
+	ReportId       string     `json:"reportId"`
+	FileId         string     `json:"fileId"`
+	UserId         string     `json:"userId"`
+	ExpiresAt      time.Time  `json:"expiresAt"`
+	Revoked        bool       `json:"revoked"`
+	Downloads      int        `json:"downloads"`
+	LastDownloadAt *time.Time `json:"lastDownloadAt,omitempty"`
+	CreatedAt      time.Time  `json:"createdAt"`
+	Url            string     `bson:"-" json:"url,omitempty"`
+}
+
+type FileShareRequest struct {
+	// ExpiresIn is the lifetime of the link, e.g. "7d". Defaults to the configured lifetime.
+	ExpiresIn string `json:"expiresIn,omitempty"`
+}
+
+// Event is published to Kafka on changes of reports and report files, if configured.
+type Event struct {
+	Id         string    `bson:"_id" json:"id"`
+	Type       string    `json:"type"`
+	Time       time.Time `json:"time"`
+	ReportId   string    `json:"reportId"`
+	ReportName string    `json:"reportName,omitempty"`
+	UserId     string    `json:"userId,omitempty"`
+	Version    int       `json:"version,omitempty"` // version of the report definition
+	FileId     string    `json:"fileId,omitempty"`
+	FileType   string    `json:"fileType,omitempty"`
+	RunId      string    `json:"runId,omitempty"`
+	Error      string    `json:"error,omitempty"`
+}
+
+// AuditEntry records an operation on a report or report file.
+type AuditEntry struct {
+	Id        string                 `bson:"_id" json:"id"`
+	Actor     string                 `json:"actor"`
+	Action    string                 `json:"action"`
+	ReportId  string                 `json:"reportId,omitempty"`
+	FileId    string                 `json:"fileId,omitempty"`
+	RequestId string                 `json:"requestId,omitempty"`
+	Changes   map[string]AuditChange `json:"changes,omitempty"`
+	Timestamp time.Time              `json:"timestamp"`
+}
+
+type AuditChange struct {
+	Before interface{} `json:"before"`
+	After  interface{} `json:"after"`
 }
 
 type FromTo = struct {
@@ -62,7 +62,12 @@ func main() {
 	util.Logger.Info(srvInfoHdl.Name(), "version", srvInfoHdl.Version())
 	util.Logger.Info("config: " + sb_util.ToJsonStr(cfg))
 
-	client := report_engine.NewClient(jsreport.NewJSReportClient(cfg.JSReport.Url, cfg.JSReport.Port), cfg)
+	client, err := report_engine.NewClient(jsreport.NewJSReportClient(cfg.JSReport.Url, cfg.JSReport.Port), cfg)
+	if err != nil {
+		util.Logger.Error("error creating reporting client", "error", err)
+		ec = 1
+		return
+	}
 
 	report_engine.InitDB(cfg.MongoUrl)
 	defer report_engine.CloseDB()
@@ -102,7 +107,23 @@ func main() {
 
 	wg := &sync.WaitGroup{}
 
-	wg.Add(3)
+	wg.Add(5)
+
+	go func() {
+		defer wg.Done()
+		err := client.RunEventPublisher(ctx)
+		if err != nil && !errors.Is(err, context.Canceled) {
+			util.Logger.Error("event publisher exited", "error", err)
+		}
+	}()
+
+	go func() {
+		defer wg.Done()
+		err := client.RunTriggers(ctx)
+		if err != nil && !errors.Is(err, context.Canceled) {
+			util.Logger.Error("trigger consumers exited", "error", err)
+		}
+	}()
 
 	go func() {
 		defer wg.Done()
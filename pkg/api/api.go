@@ -54,8 +54,8 @@ func CreateServer(cfg *config.Config, client *report_engine.Client) (r *gin.Engi
 	r.Use(cors.New(cors.Config{
 		AllowOrigins:     []string{"*"},
 		AllowMethods:     []string{"GET", "POST", "DELETE", "OPTIONS", "PUT"},
-		AllowHeaders:     []string{"Origin", "Content-Type", "Authorization"},
-		ExposeHeaders:    []string{"Content-Length"},
+		AllowHeaders:     []string{"Origin", "Content-Type", "Authorization", HeaderIfMatch},
+		ExposeHeaders:    []string{"Content-Length", HeaderETag},
 		AllowCredentials: true,
 	}))
 	var middleware []gin.HandlerFunc
@@ -71,6 +71,21 @@ func CreateServer(cfg *config.Config, client *report_engine.Client) (r *gin.Engi
 	middleware = append(middleware,
 		requestid.New(requestid.WithCustomHeaderStrKey(HeaderRequestID)),
 		gin_mw.ErrorHandler(func(err error) int {
+			if errors.Is(err, report_engine.ErrForbidden) {
+				return http.StatusForbidden
+			}
+			if errors.Is(err, report_engine.ErrNotFound) {
+				return http.StatusNotFound
+			}
+			if errors.Is(err, report_engine.ErrLinkExpired) {
+				return http.StatusGone
+			}
+			if errors.Is(err, report_engine.ErrConflict) {
+				return http.StatusConflict
+			}
+			if errors.Is(err, report_engine.ErrValidation) {
+				return http.StatusBadRequest
+			}
 			return 0
 		}, ", "),
 		gin_mw.StructRecoveryHandler(util.Logger, gin_mw.DefaultRecoveryFunc),
@@ -93,6 +108,13 @@ func CreateServer(cfg *config.Config, client *report_engine.Client) (r *gin.Engi
 	for _, route := range setRoutes {
 		util.Logger.Debug("http route", attributes.MethodKey, route[0], attributes.PathKey, route[1])
 	}
+	setRoutes, err = routesAdmin.Set(*client, prefix.Group(AdminPath, AdminMiddleware()))
+	if err != nil {
+		return nil, err
+	}
+	for _, route := range setRoutes {
+		util.Logger.Debug("http route", attributes.MethodKey, route[0], attributes.PathKey, route[1])
+	}
 	return r, nil
 }
 
@@ -109,11 +131,30 @@ func AuthMiddleware() gin.HandlerFunc {
 	}
 }
 
+func AdminMiddleware() gin.HandlerFunc {
+	return func(gc *gin.Context) {
+		if !isAdmin(gc) {
+			gc.AbortWithStatusJSON(http.StatusForbidden, gin.H{"error": "forbidden"})
+			return
+		}
+		gc.Next()
+	}
+}
+
+// isAdmin checks the roles provided by the gateway or, if missing, the realm roles of the token.
+func isAdmin(c *gin.Context) bool {
+	roles := strings.Split(c.GetHeader(HeaderUserRoles), ", ")
+	if slices.Contains[[]string](roles, AdminRole) {
+		return true
+	}
+	claims, err := jwt.Parse(c.GetHeader(HeaderAuthorization))
+	return err == nil && claims.HasRole(AdminRole)
+}
+
 func getUserId(c *gin.Context) (userId string, err error) {
 	forUser := c.Query("for_user")
 	if forUser != "" {
-		roles := strings.Split(c.GetHeader("X-User-Roles"), ", ")
-		if slices.Contains[[]string](roles, "admin") {
+		if isAdmin(c) {
 			return forUser, nil
 		}
 	}
@@ -133,3 +174,22 @@ func getUserId(c *gin.Context) (userId string, err error) {
 	}
 	return
 }
+
+// publicError hides internal error details from the client, except for errors the client can act upon.
+func publicError(err error) error {
+	if errors.Is(err, report_engine.ErrForbidden) || errors.Is(err, report_engine.ErrLinkExpired) || errors.Is(err, report_engine.ErrNotFound) ||
+		errors.Is(err, report_engine.ErrConflict) || errors.Is(err, report_engine.ErrValidation) {
+		return err
+	}
+	return errors.New(MessageSomethingWrong)
+}
+
+// revisionETag formats the revision of a report as entity tag.
+func revisionETag(revision int) string {
+	return strconv.Quote(strconv.Itoa(revision))
+}
+
+// parseRevisionETag parses an entity tag created by revisionETag.
+func parseRevisionETag(etag string) (revision int, err error) {
+	return strconv.Atoi(strings.Trim(strings.TrimPrefix(etag, "W/"), "\""))
+}
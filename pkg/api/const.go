@@ -19,11 +19,16 @@ package api
 const (
 	HeaderAuthorization = "Authorization"
 	HeaderRequestID     = "X-Request-ID"
+	HeaderUserRoles     = "X-User-Roles"
+	HeaderIfMatch       = "If-Match"
+	HeaderETag          = "ETag"
 	UserIdKey           = "UserId"
+	AdminRole           = "admin"
 )
 
 const (
 	HealthCheckPath = "/health-check"
+	AdminPath       = "/admin"
 )
 
 const (
@@ -112,12 +112,17 @@ func postReportCreate(reportingClient report_engine.Client) (string, string, gin
 			_ = c.Error(errors.New(MessageSomethingWrong))
 			return
 		}
-		result, _, err := reportingClient.CreateReportFile(request, c.GetHeader(HeaderAuthorization))
+		result, fileId, err := reportingClient.CreateReportFile(request, c.GetHeader(HeaderAuthorization))
 		if err != nil {
 			util.Logger.Error("could not create report file", "error", err)
-			_ = c.Error(errors.New(MessageSomethingWrong))
+			_ = c.Error(publicError(err))
 			return
 		}
+		recordAudit(c, reportingClient, lib.AuditEntry{Action: report_engine.AuditActionReportRun, ReportId: result.Id, FileId: fileId})
+		// the file has been created, failed deliveries are recorded in the runs of the report
+		if _, err = reportingClient.DeliverReportFile(result, fileId, c.GetHeader(HeaderAuthorization), false); err != nil {
+			util.Logger.Error("could not deliver report file "+fileId, "error", err)
+		}
 		c.JSON(http.StatusOK, gin.H{
 			"id": result.Id,
 		})
@@ -131,6 +136,7 @@ func postReportCreate(reportingClient report_engine.Client) (string, string, gin
 // @Produce json
 // @Param report body lib.Report true "Report"
 // @Success	200 {string} str
+// @Failure	400 {string} str
 // @Failure	500 {string} str
 // @Router /report [post]
 func postReport(reportingClient report_engine.Client) (string, string, gin.HandlerFunc) {
@@ -141,23 +147,26 @@ func postReport(reportingClient report_engine.Client) (string, string, gin.Handl
 			_ = c.Error(errors.New(MessageSomethingWrong))
 			return
 		}
-		_, err := reportingClient.SaveReportModel(request, c.GetHeader(HeaderAuthorization))
+		report, err := reportingClient.SaveReportModel(request, c.GetHeader(HeaderAuthorization))
 		if err != nil {
 			util.Logger.Error("could not save report", "error", err)
-			_ = c.Error(errors.New(MessageSomethingWrong))
+			_ = c.Error(publicError(err))
 			return
 		}
+		recordAudit(c, reportingClient, lib.AuditEntry{Action: report_engine.AuditActionReportCreate, ReportId: report.Id, Changes: report_engine.AuditChanges(nil, report)})
 		c.Status(http.StatusOK)
 	}
 }
 
 // putReport godoc
 // @Summary Update report model
-// @Description	Updates report model
+// @Description	Updates report model, the current revision has to be provided in the body or as If-Match header
 // @Tags Report
 // @Produce json
 // @Param report body lib.Report true "Report"
+// @Param If-Match header string false "ETag of the report"
 // @Success	200 {string} str
+// @Failure	409 {string} str
 // @Failure	500 {string} str
 // @Router /report [put]
 func putReport(reportingClient report_engine.Client) (string, string, gin.HandlerFunc) {
@@ -168,36 +177,59 @@ func putReport(reportingClient report_engine.Client) (string, string, gin.Handle
 			_ = c.Error(errors.New(MessageSomethingWrong))
 			return
 		}
+		if etag := c.GetHeader(HeaderIfMatch); etag != "" {
+			revision, err := parseRevisionETag(etag)
+			if err != nil {
+				util.Logger.Error(MessageParseError, "error", err)
+				_ = c.Error(errors.New(MessageSomethingWrong))
+				return
+			}
+			request.Revision = revision
+		}
+		before, _ := reportingClient.GetReportModel(request.Id, c.GetHeader(HeaderAuthorization))
 		err := reportingClient.UpdateReportModel(request, c.GetHeader(HeaderAuthorization))
 		if err != nil {
 			util.Logger.Error("could not update report", "error", err)
-			_ = c.Error(errors.New(MessageSomethingWrong))
+			_ = c.Error(publicError(err))
 			return
 		}
+		after, _ := reportingClient.GetReportModel(request.Id, c.GetHeader(HeaderAuthorization))
+		recordAudit(c, reportingClient, lib.AuditEntry{Action: report_engine.AuditActionReportUpdate, ReportId: request.Id, Changes: report_engine.AuditChanges(before, after)})
+		c.Header(HeaderETag, revisionETag(after.Revision))
 		c.Status(http.StatusOK)
 	}
 }
 
 // getReports godoc
 // @Summary Get all reports
-// @Description	Gets all reports
+// @Description	Gets a page of all reports readable by the user
 // @Tags Report
 // @Produce json
-// @Success	200 {array} lib.Report
+// @Param search query string false "Search in name and template name"
+// @Param templateName query string false "Template name filter, may be repeated"
+// @Param hasCron query bool false "Filter reports with or without schedule"
+// @Param createdSince query string false "RFC3339 timestamp"
+// @Param createdUntil query string false "RFC3339 timestamp"
+// @Param updatedSince query string false "RFC3339 timestamp"
+// @Param updatedUntil query string false "RFC3339 timestamp"
+// @Param lastRunStatus query string false "success, failed or never, may be repeated"
+// @Param limit query int false "Limit"
+// @Param offset query int false "Offset"
+// @Param order query string false "Order, e.g. name:asc, fields: id, name, templateName, createdAt, updatedAt, lastRun"
+// @Success	200 {object} lib.ReportPage
+// @Failure	400 {string} str
 // @Failure	500 {string} str
 // @Router /report [get]
 func getReports(reportingClient report_engine.Client) (string, string, gin.HandlerFunc) {
 	return http.MethodGet, "/report", func(c *gin.Context) {
 		args := c.Request.URL.Query()
-		reports, err := reportingClient.GetReportModels(c.GetHeader(HeaderAuthorization), args, false)
+		page, err := reportingClient.GetReportModels(c.GetHeader(HeaderAuthorization), args, false)
 		if err != nil {
 			util.Logger.Error("could not get reports", "error", err)
-			_ = c.Error(errors.New(MessageSomethingWrong))
+			_ = c.Error(publicError(err))
 			return
 		}
-		c.JSON(http.StatusOK, gin.H{
-			"data": reports,
-		})
+		c.JSON(http.StatusOK, page)
 	}
 }
 
@@ -216,9 +248,10 @@ func getReport(reportingClient report_engine.Client) (string, string, gin.Handle
 		report, err := reportingClient.GetReportModel(id, c.GetHeader(HeaderAuthorization))
 		if err != nil {
 			util.Logger.Error("could not get report "+id, "error", err)
-			_ = c.Error(errors.New(MessageSomethingWrong))
+			_ = c.Error(publicError(err))
 			return
 		}
+		c.Header(HeaderETag, revisionETag(report.Revision))
 		c.JSON(http.StatusOK, gin.H{
 			"data": report,
 		})
@@ -235,16 +268,186 @@ func getReport(reportingClient report_engine.Client) (string, string, gin.Handle
 func deleteReport(reportingClient report_engine.Client) (string, string, gin.HandlerFunc) {
 	return http.MethodDelete, "/report/:id", func(c *gin.Context) {
 		id := c.Param("id")
+		before, _ := reportingClient.GetReportModel(id, c.GetHeader(HeaderAuthorization))
 		err := reportingClient.DeleteReport(id, c.GetHeader(HeaderAuthorization), false)
 		if err != nil {
 			util.Logger.Error("could not delete reports", "error", err)
-			_ = c.Error(errors.New(MessageSomethingWrong))
+			_ = c.Error(publicError(err))
 			return
 		}
+		recordAudit(c, reportingClient, lib.AuditEntry{Action: report_engine.AuditActionReportDelete, ReportId: id, Changes: report_engine.AuditChanges(before, nil)})
 		c.Status(http.StatusNoContent)
 	}
 }
 
+// putReportPermissions godoc
+// @Summary Update report permissions
+// @Description	Replaces the permissions of other users and groups on a report, requires the administrate permission
+// @Tags Report
+// @Produce json
+// @Param id path string true "Report ID"
+// @Param permissions body lib.Permissions true "Permissions"
+// @Success	200
+// @Failure	403 {string} str
+// @Failure	500 {string} str
+// @Router /report/:id/permissions [put]
+func putReportPermissions(reportingClient report_engine.Client) (string, string, gin.HandlerFunc) {
+	return http.MethodPut, "/report/:id/permissions", func(c *gin.Context) {
+		id := c.Param("id")
+		var request lib.Permissions
+		if err := c.ShouldBindJSON(&request); err != nil {
+			util.Logger.Error(MessageParseError, "error", err)
+			_ = c.Error(errors.New(MessageSomethingWrong))
+			return
+		}
+		before, _ := reportingClient.GetReportModel(id, c.GetHeader(HeaderAuthorization))
+		err := reportingClient.SetReportPermissions(id, request, c.GetHeader(HeaderAuthorization))
+		if err != nil {
+			util.Logger.Error("could not update permissions of report "+id, "error", err)
+			_ = c.Error(publicError(err))
+			return
+		}
+		recordAudit(c, reportingClient, lib.AuditEntry{Action: report_engine.AuditActionReportPermissions, ReportId: id, Changes: report_engine.AuditChanges(
+			gin.H{"permissions": before.Permissions}, gin.H{"permissions": request},
+		)})
+		c.Status(http.StatusOK)
+	}
+}
+
+// postReportClone godoc
+// @Summary Clone report
+// @Description	Creates a new report from the definition of an existing report, optionally replacing device and service ids in all queries
+// @Tags Report
+// @Produce json
+// @Param id path string true "Report ID"
+// @Param request body lib.CloneRequest false "Overrides and id substitutions"
+// @Success	200 {object} lib.Report
+// @Failure	400 {string} str
+// @Failure	403 {string} str
+// @Failure	500 {string} str
+// @Router /report/:id/clone [post]
+func postReportClone(reportingClient report_engine.Client) (string, string, gin.HandlerFunc) {
+	return http.MethodPost, "/report/:id/clone", func(c *gin.Context) {
+		id := c.Param("id")
+		var request lib.CloneRequest
+		if c.Request.ContentLength != 0 {
+			if err := c.ShouldBindJSON(&request); err != nil {
+				util.Logger.Error(MessageParseError, "error", err)
+				_ = c.Error(errors.New(MessageSomethingWrong))
+				return
+			}
+		}
+		report, err := reportingClient.CloneReport(id, request, c.GetHeader(HeaderAuthorization))
+		if err != nil {
+			util.Logger.Error("could not clone report "+id, "error", err)
+			_ = c.Error(publicError(err))
+			return
+		}
+		changes := report_engine.AuditChanges(nil, report)
+		changes["clonedFrom"] = lib.AuditChange{After: id}
+		recordAudit(c, reportingClient, lib.AuditEntry{Action: report_engine.AuditActionReportClone, ReportId: report.Id, Changes: changes})
+		c.JSON(http.StatusOK, gin.H{
+			"data": report,
+		})
+	}
+}
+
+// getReportExport godoc
+// @Summary Export report
+// @Description	Exports the report definition as portable bundle with symbolic device references
+// @Tags Report
+// @Produce json
+// @Param id path string true "Report ID"
+// @Success	200 {object} lib.ReportBundle
+// @Failure	403 {string} str
+// @Failure	500 {string} str
+// @Router /report/:id/export [get]
+func getReportExport(reportingClient report_engine.Client) (string, string, gin.HandlerFunc) {
+	return http.MethodGet, "/report/:id/export", func(c *gin.Context) {
+		id := c.Param("id")
+		bundle, err := reportingClient.ExportReport(id, c.GetHeader(HeaderAuthorization))
+		if err != nil {
+			util.Logger.Error("could not export report "+id, "error", err)
+			_ = c.Error(publicError(err))
+			return
+		}
+		c.Header("Content-Disposition", "attachment; filename=\""+id+".json\"")
+		c.JSON(http.StatusOK, bundle)
+	}
+}
+
+// postReportImport godoc
+// @Summary Import report
+// @Description	Creates a report from an exported bundle, mapping its device references to devices of the user
+// @Tags Report
+// @Produce json
+// @Param request body lib.ImportRequest true "Bundle and device mapping"
+// @Param dryRun query bool false "Validate and return the report without saving it"
+// @Success	200 {object} lib.ImportResult
+// @Failure	400 {string} str
+// @Failure	500 {string} str
+// @Router /report/import [post]
+func postReportImport(reportingClient report_engine.Client) (string, string, gin.HandlerFunc) {
+	return http.MethodPost, "/report/import", func(c *gin.Context) {
+		var request lib.ImportRequest
+		if err := c.ShouldBindJSON(&request); err != nil {
+			util.Logger.Error(MessageParseError, "error", err)
+			_ = c.Error(errors.New(MessageSomethingWrong))
+			return
+		}
+		dryRun := c.Query("dryRun") == "true"
+		result, err := reportingClient.ImportReport(request, dryRun, c.GetHeader(HeaderAuthorization))
+		if err != nil {
+			util.Logger.Error("could not import report", "error", err)
+			_ = c.Error(publicError(err))
+			return
+		}
+		if !dryRun {
+			recordAudit(c, reportingClient, lib.AuditEntry{Action: report_engine.AuditActionReportImport, ReportId: result.Report.Id, Changes: report_engine.AuditChanges(nil, result.Report)})
+		}
+		c.JSON(http.StatusOK, gin.H{
+			"data": result,
+		})
+	}
+}
+
+// postReportBulk godoc
+// @Summary Bulk operations on reports
+// @Description	Executes operations (delete, pause, resume, run, cron, emailReceivers) on multiple reports, returning the result per report
+// @Tags Report
+// @Produce json
+// @Param request body lib.BulkRequest true "Operations"
+// @Success	200 {array} lib.BulkResult
+// @Failure	500 {string} str
+// @Router /report/bulk [post]
+func postReportBulk(reportingClient report_engine.Client) (string, string, gin.HandlerFunc) {
+	return http.MethodPost, "/report/bulk", func(c *gin.Context) {
+		var request lib.BulkRequest
+		if err := c.ShouldBindJSON(&request); err != nil {
+			util.Logger.Error(MessageParseError, "error", err)
+			_ = c.Error(errors.New(MessageSomethingWrong))
+			return
+		}
+		results, err := reportingClient.BulkReports(request, c.GetHeader(HeaderAuthorization))
+		if err != nil {
+			util.Logger.Error("could not execute bulk operations", "error", err)
+			_ = c.Error(publicError(err))
+			return
+		}
+		for i, result := range results {
+			if result.Err != nil {
+				util.Logger.Error("bulk operation failed", "action", result.Action, "report_id", result.ReportId, "error", result.Err)
+				results[i].Error = publicError(result.Err).Error()
+				continue
+			}
+			recordAudit(c, reportingClient, lib.AuditEntry{Action: report_engine.AuditActionReportBulk + result.Action, ReportId: result.ReportId, FileId: result.FileId})
+		}
+		c.JSON(http.StatusOK, gin.H{
+			"data": results,
+		})
+	}
+}
+
 // getReportFile godoc
 // @Summary Get report file by id
 // @Description	Gets report file by id
@@ -262,9 +465,10 @@ func getReportFile(reportingClient report_engine.Client) (string, string, gin.Ha
 		content, contentType, _, err := reportingClient.DownloadReportFile(reportId, fileId, c.GetHeader(HeaderAuthorization))
 		if err != nil {
 			util.Logger.Error("could not get report file "+fileId, "error", err)
-			_ = c.Error(errors.New(MessageSomethingWrong))
+			_ = c.Error(publicError(err))
 			return
 		}
+		recordAudit(c, reportingClient, lib.AuditEntry{Action: report_engine.AuditActionFileDownload, ReportId: reportId, FileId: fileId})
 		c.Data(http.StatusOK, contentType, content)
 	}
 }
@@ -283,13 +487,137 @@ func deleteReportFile(reportingClient report_engine.Client) (string, string, gin
 		err := reportingClient.DeleteCreatedReportFile(reportId, fileId, c.GetHeader(HeaderAuthorization))
 		if err != nil {
 			util.Logger.Error("could not delete report file "+fileId, "error", err)
-			_ = c.Error(errors.New(MessageSomethingWrong))
+			_ = c.Error(publicError(err))
+			return
+		}
+		recordAudit(c, reportingClient, lib.AuditEntry{Action: report_engine.AuditActionFileDelete, ReportId: reportId, FileId: fileId})
+		c.Status(http.StatusNoContent)
+	}
+}
+
+// postReportFileShare godoc
+// @Summary Create public link for report file
+// @Description	Creates a signed, expiring public download link for a report file, requires the administrate permission
+// @Tags Report
+// @Produce json
+// @Param reportId path string true "Report ID"
+// @Param fileId path string true "File ID"
+// @Param request body lib.FileShareRequest false "Share options"
+// @Success	200 {object} lib.FileShare
+// @Failure	403 {string} str
+// @Failure	500 {string} str
+// @Router /report/file/:reportId/:fileId/share [post]
+func postReportFileShare(reportingClient report_engine.Client) (string, string, gin.HandlerFunc) {
+	return http.MethodPost, "/report/file/:reportId/:fileId/share", func(c *gin.Context) {
+		reportId := c.Param("reportId")
+		fileId := c.Param("fileId")
+		var request lib.FileShareRequest
+		if c.Request.ContentLength > 0 {
+			if err := c.ShouldBindJSON(&request); err != nil {
+				util.Logger.Error(MessageParseError, "error", err)
+				_ = c.Error(errors.New(MessageSomethingWrong))
+				return
+			}
+		}
+		share, err := reportingClient.ShareReportFile(reportId, fileId, request, c.GetHeader(HeaderAuthorization))
+		if err != nil {
+			util.Logger.Error("could not share report file "+fileId, "error", err)
+			_ = c.Error(publicError(err))
+			return
+		}
+		recordAudit(c, reportingClient, lib.AuditEntry{Action: report_engine.AuditActionFileShare, ReportId: reportId, FileId: fileId, Changes: map[string]lib.AuditChange{
+			"share": {After: gin.H{"id": share.Id, "expiresAt": share.ExpiresAt}},
+		}})
+		c.JSON(http.StatusOK, gin.H{
+			"data": share,
+		})
+	}
+}
+
+// getReportFileShares godoc
+// @Summary Get public links of report file
+// @Description	Gets all public download links of a report file including their download count, requires the administrate permission
+// @Tags Report
+// @Produce json
+// @Param reportId path string true "Report ID"
+// @Param fileId path string true "File ID"
+// @Success	200 {array} lib.FileShare
+// @Failure	403 {string} str
+// @Failure	500 {string} str
+// @Router /report/file/:reportId/:fileId/share [get]
+func getReportFileShares(reportingClient report_engine.Client) (string, string, gin.HandlerFunc) {
+	return http.MethodGet, "/report/file/:reportId/:fileId/share", func(c *gin.Context) {
+		reportId := c.Param("reportId")
+		fileId := c.Param("fileId")
+		shares, err := reportingClient.GetReportFileShares(reportId, fileId, c.GetHeader(HeaderAuthorization))
+		if err != nil {
+			util.Logger.Error("could not get shares of report file "+fileId, "error", err)
+			_ = c.Error(publicError(err))
+			return
+		}
+		c.JSON(http.StatusOK, gin.H{
+			"data": shares,
+		})
+	}
+}
+
+// deleteReportFileShare godoc
+// @Summary Revoke public link of report file
+// @Description	Revokes a public download link of a report file, requires the administrate permission
+// @Tags Report
+// @Param reportId path string true "Report ID"
+// @Param fileId path string true "File ID"
+// @Param shareId path string true "Share ID"
+// @Success	204
+// @Failure	403 {string} str
+// @Failure	500 {string} str
+// @Router /report/file/:reportId/:fileId/share/:shareId [delete]
+func deleteReportFileShare(reportingClient report_engine.Client) (string, string, gin.HandlerFunc) {
+	return http.MethodDelete, "/report/file/:reportId/:fileId/share/:shareId", func(c *gin.Context) {
+		reportId := c.Param("reportId")
+		fileId := c.Param("fileId")
+		shareId := c.Param("shareId")
+		err := reportingClient.RevokeReportFileShare(reportId, fileId, shareId, c.GetHeader(HeaderAuthorization))
+		if err != nil {
+			util.Logger.Error("could not revoke share "+shareId, "error", err)
+			_ = c.Error(publicError(err))
 			return
 		}
+		recordAudit(c, reportingClient, lib.AuditEntry{Action: report_engine.AuditActionFileShareRevoke, ReportId: reportId, FileId: fileId, Changes: map[string]lib.AuditChange{
+			"share": {Before: gin.H{"id": shareId}},
+		}})
 		c.Status(http.StatusNoContent)
 	}
 }
 
+// getSharedReportFile godoc
+// @Summary Download report file by public link
+// @Description	Downloads a report file by a signed public link, no authentication required
+// @Tags Report
+// @Param shareId path string true "Share ID"
+// @Param expires query string true "Expiration timestamp"
+// @Param signature query string true "Signature"
+// @Success	200
+// @Failure	403 {string} str
+// @Failure	410 {string} str
+// @Failure	500 {string} str
+// @Router /shared/file/:shareId [get]
+func getSharedReportFile(reportingClient report_engine.Client) (string, string, gin.HandlerFunc) {
+	return http.MethodGet, "/shared/file/:shareId", func(c *gin.Context) {
+		shareId := c.Param("shareId")
+		share, content, contentType, fileTypeExtension, err := reportingClient.DownloadSharedReportFile(shareId, c.Query("expires"), c.Query("signature"))
+		if err != nil {
+			util.Logger.Error("could not download shared report file "+shareId, "error", err)
+			_ = c.Error(publicError(err))
+			return
+		}
+		c.Set(UserIdKey, "share:"+shareId)
+		recordAudit(c, reportingClient, lib.AuditEntry{Action: report_engine.AuditActionFileSharedGet, ReportId: share.ReportId, FileId: share.FileId})
+		c.Header("Content-Disposition", "attachment; filename=\""+shareId+"."+fileTypeExtension+"\"")
+		c.Data(http.StatusOK, contentType, content)
+	}
+}
+
 func getHealthCheckH(_ report_engine.Client) (string, string, gin.HandlerFunc) {
 	return http.MethodGet, HealthCheckPath, func(c *gin.Context) {
 		c.Status(http.StatusOK)
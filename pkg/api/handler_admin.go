@@ -0,0 +1,176 @@
+/*
+ * Copyright 2025 InfAI (CC SES)
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package api
+
+import (
+	"net/http"
+
+	"github.com/SENERGY-Platform/reporting-service/lib"
+	"github.com/SENERGY-Platform/reporting-service/pkg/report_engine"
+	"github.com/SENERGY-Platform/reporting-service/pkg/util"
+	"github.com/gin-contrib/requestid"
+	"github.com/gin-gonic/gin"
+)
+
+// getAdminReports godoc
+// @Summary Get reports of all users
+// @Description	Gets reports of all users, requires the admin role
+// @Tags Admin
+// @Produce json
+// @Param userId query string false "Owner filter, may be repeated"
+// @Param search query string false "Search in name and template name"
+// @Param templateName query string false "Template name filter, may be repeated"
+// @Param hasCron query bool false "Filter reports with or without schedule"
+// @Param createdSince query string false "RFC3339 timestamp"
+// @Param createdUntil query string false "RFC3339 timestamp"
+// @Param updatedSince query string false "RFC3339 timestamp"
+// @Param updatedUntil query string false "RFC3339 timestamp"
+// @Param lastRunStatus query string false "success, failed or never, may be repeated"
+// @Param limit query int false "Limit"
+// @Param offset query int false "Offset"
+// @Param order query string false "Order, e.g. name:asc, fields: id, name, templateName, createdAt, updatedAt, lastRun"
+// @Success	200 {object} lib.ReportPage
+// @Failure	400 {string} str
+// @Failure	403 {string} str
+// @Failure	500 {string} str
+// @Router /admin/reports [get]
+func getAdminReports(reportingClient report_engine.Client) (string, string, gin.HandlerFunc) {
+	return http.MethodGet, "/reports", func(c *gin.Context) {
+		args := c.Request.URL.Query()
+		page, err := reportingClient.GetReportModels(c.GetHeader(HeaderAuthorization), args, true)
+		auditAdminAction(c, reportingClient, "list", "", err)
+		if err != nil {
+			_ = c.Error(publicError(err))
+			return
+		}
+		c.JSON(http.StatusOK, page)
+	}
+}
+
+// getAdminReport godoc
+// @Summary Get report of any user
+// @Description	Gets a report of any user including its run history, requires the admin role
+// @Tags Admin
+// @Produce json
+// @Param id path string true "Report ID"
+// @Success	200 {object} lib.Report
+// @Failure	403 {string} str
+// @Failure	404 {string} str
+// @Failure	500 {string} str
+// @Router /admin/reports/:id [get]
+func getAdminReport(reportingClient report_engine.Client) (string, string, gin.HandlerFunc) {
+	return http.MethodGet, "/reports/:id", func(c *gin.Context) {
+		id := c.Param("id")
+		report, err := reportingClient.AdminGetReportModel(id)
+		auditAdminAction(c, reportingClient, "get", id, err)
+		if err != nil {
+			_ = c.Error(publicError(err))
+			return
+		}
+		c.JSON(http.StatusOK, gin.H{
+			"data": report,
+		})
+	}
+}
+
+// getAdminReportRuns godoc
+// @Summary Get runs of a report of any user
+// @Description	Gets the runs of a report with the outcome of every delivery, latest first, requires the admin role
+// @Tags Admin
+// @Produce json
+// @Param id path string true "Report ID"
+// @Param limit query int false "Limit"
+// @Param offset query int false "Offset"
+// @Success	200 {array} lib.ReportRun
+// @Failure	403 {string} str
+// @Failure	404 {string} str
+// @Failure	500 {string} str
+// @Router /admin/reports/:id/runs [get]
+func getAdminReportRuns(reportingClient report_engine.Client) (string, string, gin.HandlerFunc) {
+	return http.MethodGet, "/reports/:id/runs", func(c *gin.Context) {
+		id := c.Param("id")
+		runs, err := reportingClient.AdminGetReportRuns(id, c.Request.URL.Query())
+		auditAdminAction(c, reportingClient, "runs", id, err)
+		if err != nil {
+			_ = c.Error(publicError(err))
+			return
+		}
+		c.JSON(http.StatusOK, gin.H{
+			"data": runs,
+		})
+	}
+}
+
+// postAdminReportRun godoc
+// @Summary Run report of any user
+// @Description	Creates a report file on behalf of the report owner and emails it, requires the admin role
+// @Tags Admin
+// @Produce json
+// @Param id path string true "Report ID"
+// @Success	200 {string} str
+// @Failure	403 {string} str
+// @Failure	404 {string} str
+// @Failure	500 {string} str
+// @Router /admin/reports/:id/run [post]
+func postAdminReportRun(reportingClient report_engine.Client) (string, string, gin.HandlerFunc) {
+	return http.MethodPost, "/reports/:id/run", func(c *gin.Context) {
+		id := c.Param("id")
+		fileId, err := reportingClient.AdminRunReport(id)
+		auditAdminAction(c, reportingClient, report_engine.AuditActionReportRun, id, err)
+		if err != nil {
+			_ = c.Error(publicError(err))
+			return
+		}
+		c.JSON(http.StatusOK, gin.H{
+			"fileId": fileId,
+		})
+	}
+}
+
+// deleteAdminReport godoc
+// @Summary Delete report of any user
+// @Description	Deletes a report and its files on behalf of the report owner, requires the admin role
+// @Tags Admin
+// @Param id path string true "Report ID"
+// @Success	204
+// @Failure	403 {string} str
+// @Failure	404 {string} str
+// @Failure	500 {string} str
+// @Router /admin/reports/:id [delete]
+func deleteAdminReport(reportingClient report_engine.Client) (string, string, gin.HandlerFunc) {
+	return http.MethodDelete, "/reports/:id", func(c *gin.Context) {
+		id := c.Param("id")
+		err := reportingClient.AdminDeleteReport(id)
+		auditAdminAction(c, reportingClient, report_engine.AuditActionReportDelete, id, err)
+		if err != nil {
+			_ = c.Error(publicError(err))
+			return
+		}
+		c.Status(http.StatusNoContent)
+	}
+}
+
+// auditAdminAction logs every admin action and records successful ones in the audit log.
+func auditAdminAction(c *gin.Context, reportingClient report_engine.Client, action string, reportId string, err error) {
+	attrs := []any{"action", action, "admin_id", c.GetString(UserIdKey), "report_id", reportId, "request_id", requestid.Get(c)}
+	if err != nil {
+		util.Logger.Error("admin action failed", append(attrs, "error", err)...)
+		return
+	}
+	util.Logger.Info("admin action", attrs...)
+	recordAudit(c, reportingClient, lib.AuditEntry{Action: "admin." + action, ReportId: reportId})
+}
@@ -0,0 +1,131 @@
+/*
+ * Copyright 2025 InfAI (CC SES)
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package api
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/SENERGY-Platform/reporting-service/lib"
+	"github.com/SENERGY-Platform/reporting-service/pkg/report_engine"
+	"github.com/SENERGY-Platform/reporting-service/pkg/util"
+	"github.com/gin-contrib/requestid"
+	"github.com/gin-gonic/gin"
+)
+
+// getAudit godoc
+// @Summary Get audit log
+// @Description	Gets the audit log of all reports, requires the admin role
+// @Tags Audit
+// @Produce json
+// @Param actor query string false "Actor filter"
+// @Param action query string false "Action filter, may be repeated"
+// @Param reportId query string false "Report filter"
+// @Param since query string false "RFC 3339 timestamp"
+// @Param until query string false "RFC 3339 timestamp"
+// @Param limit query int false "Limit"
+// @Param offset query int false "Offset"
+// @Success	200 {array} lib.AuditEntry
+// @Failure	403 {string} str
+// @Failure	500 {string} str
+// @Router /audit [get]
+func getAudit(reportingClient report_engine.Client) (string, string, gin.HandlerFunc) {
+	return http.MethodGet, "/audit", func(c *gin.Context) {
+		if !isAdmin(c) {
+			_ = c.Error(report_engine.ErrForbidden)
+			return
+		}
+		entries, err := reportingClient.GetAuditEntries(c.Request.URL.Query())
+		if err != nil {
+			util.Logger.Error("could not get audit entries", "error", err)
+			_ = c.Error(errors.New(MessageSomethingWrong))
+			return
+		}
+		c.JSON(http.StatusOK, gin.H{
+			"data": entries,
+		})
+	}
+}
+
+// getReportAudit godoc
+// @Summary Get audit log of report
+// @Description	Gets the audit log of a report, requires the administrate permission
+// @Tags Audit
+// @Produce json
+// @Param id path string true "Report ID"
+// @Param actor query string false "Actor filter"
+// @Param action query string false "Action filter, may be repeated"
+// @Param since query string false "RFC 3339 timestamp"
+// @Param until query string false "RFC 3339 timestamp"
+// @Param limit query int false "Limit"
+// @Param offset query int false "Offset"
+// @Success	200 {array} lib.AuditEntry
+// @Failure	403 {string} str
+// @Failure	500 {string} str
+// @Router /report/:id/audit [get]
+func getReportAudit(reportingClient report_engine.Client) (string, string, gin.HandlerFunc) {
+	return http.MethodGet, "/report/:id/audit", func(c *gin.Context) {
+		id := c.Param("id")
+		entries, err := reportingClient.GetReportAuditEntries(id, c.Request.URL.Query(), c.GetHeader(HeaderAuthorization))
+		if err != nil {
+			util.Logger.Error("could not get audit entries of report "+id, "error", err)
+			_ = c.Error(publicError(err))
+			return
+		}
+		c.JSON(http.StatusOK, gin.H{
+			"data": entries,
+		})
+	}
+}
+
+// getReportRuns godoc
+// @Summary Get report runs
+// @Description	Gets the runs of a report with the outcome of every email and webhook delivery, latest first
+// @Tags Report
+// @Produce json
+// @Param id path string true "Report ID"
+// @Param limit query int false "Limit"
+// @Param offset query int false "Offset"
+// @Success	200 {array} lib.ReportRun
+// @Failure	403 {string} str
+// @Failure	500 {string} str
+// @Router /report/:id/runs [get]
+func getReportRuns(reportingClient report_engine.Client) (string, string, gin.HandlerFunc) {
+	return http.MethodGet, "/report/:id/runs", func(c *gin.Context) {
+		id := c.Param("id")
+		runs, err := reportingClient.GetReportRuns(id, c.Request.URL.Query(), c.GetHeader(HeaderAuthorization))
+		if err != nil {
+			util.Logger.Error("could not get runs of report "+id, "error", err)
+			_ = c.Error(publicError(err))
+			return
+		}
+		c.JSON(http.StatusOK, gin.H{
+			"data": runs,
+		})
+	}
+}
+
+// recordAudit records a successful operation with the requesting user and request id.
+// Failures are logged only, the operation itself has already been executed.
+func recordAudit(c *gin.Context, reportingClient report_engine.Client, entry lib.AuditEntry) {
+	entry.Actor = c.GetString(UserIdKey)
+	entry.RequestId = requestid.Get(c)
+	err := reportingClient.RecordAudit(entry)
+	if err != nil {
+		util.Logger.Error("could not record audit entry", "error", err, "action", entry.Action, "report_id", entry.ReportId)
+	}
+}
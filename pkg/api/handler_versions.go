@@ -0,0 +1,158 @@
+/*
+ * Copyright 2025 InfAI (CC SES)
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package api
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/SENERGY-Platform/reporting-service/lib"
+	"github.com/SENERGY-Platform/reporting-service/pkg/report_engine"
+	"github.com/SENERGY-Platform/reporting-service/pkg/util"
+	"github.com/gin-gonic/gin"
+)
+
+// getReportVersions godoc
+// @Summary Get report versions
+// @Description	Gets all saved versions of a report definition, latest first
+// @Tags Report
+// @Produce json
+// @Param id path string true "Report ID"
+// @Success	200 {array} lib.ReportVersion
+// @Failure	403 {string} str
+// @Failure	500 {string} str
+// @Router /report/:id/versions [get]
+func getReportVersions(reportingClient report_engine.Client) (string, string, gin.HandlerFunc) {
+	return http.MethodGet, "/report/:id/versions", func(c *gin.Context) {
+		id := c.Param("id")
+		versions, err := reportingClient.GetReportVersions(id, c.GetHeader(HeaderAuthorization))
+		if err != nil {
+			util.Logger.Error("could not get versions of report "+id, "error", err)
+			_ = c.Error(publicError(err))
+			return
+		}
+		c.JSON(http.StatusOK, gin.H{
+			"data": versions,
+		})
+	}
+}
+
+// getReportVersion godoc
+// @Summary Get report version
+// @Description	Gets a saved version of a report definition
+// @Tags Report
+// @Produce json
+// @Param id path string true "Report ID"
+// @Param version path int true "Version"
+// @Success	200 {object} lib.ReportVersion
+// @Failure	403 {string} str
+// @Failure	500 {string} str
+// @Router /report/:id/versions/:version [get]
+func getReportVersion(reportingClient report_engine.Client) (string, string, gin.HandlerFunc) {
+	return http.MethodGet, "/report/:id/versions/:version", func(c *gin.Context) {
+		id := c.Param("id")
+		version, err := strconv.Atoi(c.Param("version"))
+		if err != nil {
+			util.Logger.Error(MessageParseError, "error", err)
+			_ = c.Error(errors.New(MessageSomethingWrong))
+			return
+		}
+		reportVersion, err := reportingClient.GetReportVersion(id, version, c.GetHeader(HeaderAuthorization))
+		if err != nil {
+			util.Logger.Error("could not get version of report "+id, "error", err)
+			_ = c.Error(publicError(err))
+			return
+		}
+		c.JSON(http.StatusOK, gin.H{
+			"data": reportVersion,
+		})
+	}
+}
+
+// getReportVersionDiff godoc
+// @Summary Compare report versions
+// @Description	Gets the changed fields between two saved versions of a report definition
+// @Tags Report
+// @Produce json
+// @Param id path string true "Report ID"
+// @Param version path int true "Version to compare from"
+// @Param other path int true "Version to compare to"
+// @Success	200 {object} map[string]lib.AuditChange
+// @Failure	403 {string} str
+// @Failure	500 {string} str
+// @Router /report/:id/versions/:version/diff/:other [get]
+func getReportVersionDiff(reportingClient report_engine.Client) (string, string, gin.HandlerFunc) {
+	return http.MethodGet, "/report/:id/versions/:version/diff/:other", func(c *gin.Context) {
+		id := c.Param("id")
+		from, err := strconv.Atoi(c.Param("version"))
+		if err != nil {
+			util.Logger.Error(MessageParseError, "error", err)
+			_ = c.Error(errors.New(MessageSomethingWrong))
+			return
+		}
+		to, err := strconv.Atoi(c.Param("other"))
+		if err != nil {
+			util.Logger.Error(MessageParseError, "error", err)
+			_ = c.Error(errors.New(MessageSomethingWrong))
+			return
+		}
+		changes, err := reportingClient.DiffReportVersions(id, from, to, c.GetHeader(HeaderAuthorization))
+		if err != nil {
+			util.Logger.Error("could not compare versions of report "+id, "error", err)
+			_ = c.Error(publicError(err))
+			return
+		}
+		c.JSON(http.StatusOK, gin.H{
+			"data": changes,
+		})
+	}
+}
+
+// postReportVersionRestore godoc
+// @Summary Restore report version
+// @Description	Replaces the report definition with a saved version, requires the administrate permission
+// @Tags Report
+// @Produce json
+// @Param id path string true "Report ID"
+// @Param version path int true "Version"
+// @Success	200 {object} lib.Report
+// @Failure	403 {string} str
+// @Failure	500 {string} str
+// @Router /report/:id/versions/:version/restore [post]
+func postReportVersionRestore(reportingClient report_engine.Client) (string, string, gin.HandlerFunc) {
+	return http.MethodPost, "/report/:id/versions/:version/restore", func(c *gin.Context) {
+		id := c.Param("id")
+		version, err := strconv.Atoi(c.Param("version"))
+		if err != nil {
+			util.Logger.Error(MessageParseError, "error", err)
+			_ = c.Error(errors.New(MessageSomethingWrong))
+			return
+		}
+		before, _ := reportingClient.GetReportModel(id, c.GetHeader(HeaderAuthorization))
+		report, err := reportingClient.RestoreReportVersion(id, version, c.GetHeader(HeaderAuthorization))
+		if err != nil {
+			util.Logger.Error("could not restore version of report "+id, "error", err)
+			_ = c.Error(publicError(err))
+			return
+		}
+		recordAudit(c, reportingClient, lib.AuditEntry{Action: report_engine.AuditActionReportRestore, ReportId: id, Changes: report_engine.AuditChanges(before, report)})
+		c.JSON(http.StatusOK, gin.H{
+			"data": report,
+		})
+	}
+}
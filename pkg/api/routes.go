@@ -24,6 +24,7 @@ import (
 var routes = gin_mw.Routes[report_engine.Client]{
 	getHealthCheckH,
 	getSwaggerDocH,
+	getSharedReportFile,
 }
 
 var routesAuth = gin_mw.Routes[report_engine.Client]{
@@ -36,6 +37,29 @@ var routesAuth = gin_mw.Routes[report_engine.Client]{
 	getReports,
 	getReport,
 	deleteReport,
+	putReportPermissions,
+	postReportClone,
+	getReportExport,
+	postReportImport,
+	postReportBulk,
+	getReportAudit,
+	getReportRuns,
+	getReportVersions,
+	getReportVersion,
+	getReportVersionDiff,
+	postReportVersionRestore,
+	getAudit,
 	getReportFile,
 	deleteReportFile,
+	postReportFileShare,
+	getReportFileShares,
+	deleteReportFileShare,
+}
+
+var routesAdmin = gin_mw.Routes[report_engine.Client]{
+	getAdminReports,
+	getAdminReport,
+	getAdminReportRuns,
+	postAdminReportRun,
+	deleteAdminReport,
 }
@@ -17,10 +17,12 @@
 package connection_log
 
 import (
+	"context"
 	"encoding/json"
 	"errors"
 	"fmt"
 	"net/http"
+	"sync"
 	"time"
 
 	connectionLogModels "github.com/SENERGY-Platform/connection-log/pkg/model"
@@ -28,22 +30,87 @@ import (
 )
 
 type Client struct {
-	Url        string
-	Port       int64
-	BaseUrl    string
-	HttpClient *resty.Client
+	Url            string
+	Port           int64
+	BaseUrl        string
+	HttpClient     *resty.Client
+	ChunkSize      int
+	MaxConcurrency int
+	RequestTimeout time.Duration
 }
 
-func NewClient(url string, port int64) *Client {
+func NewClient(url string, port int64, chunkSize int, maxConcurrency int, requestTimeout time.Duration) *Client {
 	client := resty.New()
-	return &Client{Url: url, Port: port, BaseUrl: fmt.Sprintf("%v:%v", url, port), HttpClient: client}
+	return &Client{Url: url, Port: port, BaseUrl: fmt.Sprintf("%v:%v", url, port), HttpClient: client, ChunkSize: chunkSize, MaxConcurrency: maxConcurrency, RequestTimeout: requestTimeout}
 }
 
-func (s *Client) Query(authTokenString string, ids []string, duration time.Duration) (data []connectionLogModels.ResourceHistoricalStates, err error) {
-	dur := connectionLogModels.Duration(duration)
+// Query retrieves the historical states of the given devices between since and until.
+// The ids are split into chunks of ChunkSize, which are requested concurrently.
+// The result is ordered like the given ids, devices without a result are omitted.
+func (s *Client) Query(ctx context.Context, authTokenString string, ids []string, since time.Time, until time.Time) (data []connectionLogModels.ResourceHistoricalStates, err error) {
+	chunkSize := s.ChunkSize
+	if chunkSize <= 0 {
+		chunkSize = len(ids)
+	}
+	maxConcurrency := max(s.MaxConcurrency, 1)
+	var chunks [][]string
+	for start := 0; start < len(ids); start += chunkSize {
+		chunks = append(chunks, ids[start:min(start+chunkSize, len(ids))])
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	results := make([][]connectionLogModels.ResourceHistoricalStates, len(chunks))
+	once := sync.Once{}
+	semaphore := make(chan struct{}, maxConcurrency)
+	wg := sync.WaitGroup{}
+	for i, chunk := range chunks {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			semaphore <- struct{}{}
+			defer func() { <-semaphore }()
+			var chunkErr error
+			results[i], chunkErr = s.queryChunk(ctx, authTokenString, chunk, since, until)
+			if chunkErr != nil {
+				// keep the first error only, the remaining chunks are canceled
+				once.Do(func() {
+					err = chunkErr
+					cancel()
+				})
+			}
+		}()
+	}
+	wg.Wait()
+	if err != nil {
+		return nil, err
+	}
+
+	// merge the chunk results in device order
+	statesById := make(map[string]connectionLogModels.ResourceHistoricalStates, len(ids))
+	for _, result := range results {
+		for _, states := range result {
+			statesById[states.ID] = states
+		}
+	}
+	for _, id := range ids {
+		if states, ok := statesById[id]; ok {
+			data = append(data, states)
+		}
+	}
+	return
+}
+
+func (s *Client) queryChunk(ctx context.Context, authTokenString string, ids []string, since time.Time, until time.Time) (data []connectionLogModels.ResourceHistoricalStates, err error) {
+	if s.RequestTimeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, s.RequestTimeout)
+		defer cancel()
+	}
 	response, err := s.HttpClient.R().
+		SetContext(ctx).
 		SetHeader("Authorization", authTokenString).
-		SetBody(connectionLogModels.QueryHistorical{QueryBase: connectionLogModels.QueryBase{IDs: ids}, Range: dur}).
+		SetBody(connectionLogModels.QueryHistorical{QueryBase: connectionLogModels.QueryBase{IDs: ids}, Since: since, Until: until}).
 		Post(s.BaseUrl + "/connection-log/historical/query/list")
 	if err != nil {
 		return
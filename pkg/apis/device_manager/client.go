@@ -17,36 +17,108 @@
 package device_manager
 
 import (
+	"context"
 	"encoding/json"
 	"errors"
 	"fmt"
 	"net/http"
+	"net/url"
+	"strconv"
+	"time"
 
 	snrgyModels "github.com/SENERGY-Platform/models/go/models"
 	"github.com/go-resty/resty/v2"
 )
 
+var ErrTooManyDevices = errors.New("too many devices")
+var ErrNotFound = errors.New("device not found")
+
 type Client struct {
-	Url        string
-	Port       int64
-	BaseUrl    string
-	HttpClient *resty.Client
+	Url            string
+	Port           int64
+	BaseUrl        string
+	HttpClient     *resty.Client
+	PageSize       int
+	MaxDevices     int
+	RequestTimeout time.Duration
 }
 
-func NewClient(url string, port int64) *Client {
+func NewClient(url string, port int64, pageSize int, maxDevices int, requestTimeout time.Duration) *Client {
 	client := resty.New()
-	return &Client{Url: url, Port: port, BaseUrl: fmt.Sprintf("%v:%v", url, port), HttpClient: client}
+	return &Client{Url: url, Port: port, BaseUrl: fmt.Sprintf("%v:%v", url, port), HttpClient: client, PageSize: pageSize, MaxDevices: maxDevices, RequestTimeout: requestTimeout}
+}
+
+// Query pages through all devices of the user and passes every page to the handler, as soon as it is received.
+// Returns ErrTooManyDevices if the user has more than MaxDevices devices.
+func (s *Client) Query(ctx context.Context, authTokenString string, handler func(devices []snrgyModels.Device) error) (err error) {
+	if s.PageSize <= 0 {
+		return errors.New("device_manager.client - invalid page size")
+	}
+	count := 0
+	for offset := 0; ; offset += s.PageSize {
+		var devices []snrgyModels.Device
+		devices, err = s.queryPage(ctx, authTokenString, offset)
+		if err != nil {
+			return
+		}
+		count += len(devices)
+		if s.MaxDevices > 0 && count > s.MaxDevices {
+			return fmt.Errorf("device_manager.client - %w: more than %v devices found", ErrTooManyDevices, s.MaxDevices)
+		}
+		if len(devices) > 0 {
+			err = handler(devices)
+			if err != nil {
+				return
+			}
+		}
+		if len(devices) < s.PageSize {
+			return
+		}
+	}
+}
+
+// Get returns a device of the user. Returns ErrNotFound if the device does not exist or the user may not read it.
+func (s *Client) Get(ctx context.Context, authTokenString string, id string) (device snrgyModels.Device, err error) {
+	if s.RequestTimeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, s.RequestTimeout)
+		defer cancel()
+	}
+	response, err := s.HttpClient.R().
+		SetContext(ctx).
+		SetHeader("Authorization", authTokenString).
+		Get(s.BaseUrl + "/device-manager/devices/" + url.PathEscape(id))
+	if err != nil {
+		return
+	}
+	if response.StatusCode() == http.StatusNotFound || response.StatusCode() == http.StatusForbidden {
+		return device, fmt.Errorf("device_manager.client - %w: %v", ErrNotFound, id)
+	}
+	if response.StatusCode() != http.StatusOK {
+		return device, errors.New("device_manager.client - response code error: " + response.String())
+	}
+	err = json.Unmarshal(response.Body(), &device)
+	return
 }
 
-func (s *Client) Query(authTokenString string) (data []snrgyModels.Device, err error) {
+func (s *Client) queryPage(ctx context.Context, authTokenString string, offset int) (data []snrgyModels.Device, err error) {
+	if s.RequestTimeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, s.RequestTimeout)
+		defer cancel()
+	}
 	response, err := s.HttpClient.R().
+		SetContext(ctx).
 		SetHeader("Authorization", authTokenString).
+		SetQueryParam("limit", strconv.Itoa(s.PageSize)).
+		SetQueryParam("offset", strconv.Itoa(offset)).
+		SetQueryParam("sort", "name.asc").
 		Get(s.BaseUrl + "/device-manager/devices")
 	if err != nil {
 		return
 	}
 	if response.StatusCode() != http.StatusOK {
-		return data, errors.New("senergy_devices.client - response code error: " + response.String())
+		return data, errors.New("device_manager.client - response code error: " + response.String())
 	}
 	err = json.Unmarshal(response.Body(), &data)
 	return
@@ -47,6 +47,10 @@ func NewJSReportClient(url string, port int64) *Client {
 	return &Client{Url: url, Port: port, BaseUrl: fmt.Sprintf("%v:%v", url, port), HttpClient: client}
 }
 
+func (j *Client) Name() string {
+	return "jsreport"
+}
+
 func (j *Client) GetTemplates(authString string) (templates []lib.Template, err error) {
 	response, err := j.HttpClient.R().SetHeader("Authorization", authString).Get(j.BaseUrl + "/odata/templates?$select=name,recipe")
 	if err != nil {
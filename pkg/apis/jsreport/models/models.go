@@ -22,9 +22,42 @@ import (
 
 type DeviceState struct {
 	snrgyModels.Device
-	DisplayName string     `json:"display_name"`
-	LogHistory  LogHistory `json:"log_history"`
+	DisplayName  string       `json:"display_name"`
+	LogHistory   LogHistory   `json:"log_history"`
+	Availability Availability `json:"availability"`
 }
 type LogHistory struct {
 	Values [][3]interface{} `json:"values"`
 }
+
+// Availability holds the connection statistics of a single device within the queried window.
+// All durations are given in seconds. AvailabilityPercent is nil if no state is known for the window.
+type Availability struct {
+	AvailabilityPercent  *float64 `json:"availability_percent"`
+	Disconnects          int      `json:"disconnects"`
+	LongestOutageSeconds int64    `json:"longest_outage_seconds"`
+	MTBFSeconds          *int64   `json:"mtbf_seconds"`
+	OfflineSeconds       int64    `json:"offline_seconds"`
+	Online               bool     `json:"online"`
+	ObservedSeconds      int64    `json:"observed_seconds"`
+	UptimeSeconds        int64    `json:"uptime_seconds"`
+}
+
+// AvailabilitySummary aggregates the Availability of all devices of a device query.
+// OfflineSeconds is the longest current outage of all offline devices.
+type AvailabilitySummary struct {
+	Devices              int      `json:"devices"`
+	OnlineDevices        int      `json:"online_devices"`
+	OfflineDevices       int      `json:"offline_devices"`
+	AvailabilityPercent  *float64 `json:"availability_percent"`
+	Disconnects          int      `json:"disconnects"`
+	LongestOutageSeconds int64    `json:"longest_outage_seconds"`
+	MTBFSeconds          *int64   `json:"mtbf_seconds"`
+	OfflineSeconds       int64    `json:"offline_seconds"`
+}
+
+// DeviceStatesSummary is the result of a device query with summary, the device list with aggregated statistics.
+type DeviceStatesSummary struct {
+	Devices      []DeviceState       `json:"devices"`
+	Availability AvailabilitySummary `json:"availability"`
+}
@@ -0,0 +1,53 @@
+/*
+ * Copyright 2025 InfAI (CC SES)
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package kafka
+
+import (
+	"context"
+	"strings"
+
+	"github.com/segmentio/kafka-go"
+)
+
+// Consumer reads the messages of a topic as member of a consumer group.
+// Instances with the same group share the partitions of the topic, so every message is read by one instance.
+type Consumer struct {
+	reader *kafka.Reader
+}
+
+// NewConsumer creates a consumer for the comma separated brokers in bootstrap. New groups start at the latest offset.
+func NewConsumer(bootstrap string, groupId string, topic string) *Consumer {
+	return &Consumer{reader: kafka.NewReader(kafka.ReaderConfig{
+		Brokers:     strings.Split(bootstrap, ","),
+		GroupID:     groupId,
+		Topic:       topic,
+		StartOffset: kafka.LastOffset,
+	})}
+}
+
+// Read blocks until the next message is available and commits its offset.
+func (c *Consumer) Read(ctx context.Context) (message Message, err error) {
+	m, err := c.reader.ReadMessage(ctx)
+	if err != nil {
+		return
+	}
+	return Message{Topic: m.Topic, Key: string(m.Key), Value: m.Value}, nil
+}
+
+func (c *Consumer) Close() error {
+	return c.reader.Close()
+}
@@ -0,0 +1,62 @@
+/*
+ * Copyright 2025 InfAI (CC SES)
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package kafka
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	"github.com/segmentio/kafka-go"
+)
+
+// Message is published to Topic. Messages with the same key are published to the same partition, which keeps their order.
+type Message struct {
+	Topic string
+	Key   string
+	Value []byte
+}
+
+// Publisher writes messages to Kafka topics.
+type Publisher struct {
+	writer *kafka.Writer
+}
+
+// NewPublisher creates a publisher for the comma separated brokers in bootstrap. Topics are created if missing.
+func NewPublisher(bootstrap string, timeout time.Duration) *Publisher {
+	return &Publisher{writer: &kafka.Writer{
+		Addr:                   kafka.TCP(strings.Split(bootstrap, ",")...),
+		Balancer:               &kafka.Hash{},
+		RequiredAcks:           kafka.RequireAll,
+		AllowAutoTopicCreation: true,
+		WriteTimeout:           timeout,
+		BatchTimeout:           10 * time.Millisecond,
+	}}
+}
+
+// Publish writes all messages and returns after they have been acknowledged.
+func (p *Publisher) Publish(ctx context.Context, messages []Message) error {
+	kafkaMessages := make([]kafka.Message, 0, len(messages))
+	for _, message := range messages {
+		kafkaMessages = append(kafkaMessages, kafka.Message{Topic: message.Topic, Key: []byte(message.Key), Value: message.Value})
+	}
+	return p.writer.WriteMessages(ctx, kafkaMessages...)
+}
+
+func (p *Publisher) Close() error {
+	return p.writer.Close()
+}
@@ -0,0 +1,77 @@
+/*
+ * Copyright 2025 InfAI (CC SES)
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package mailer
+
+import (
+	"fmt"
+
+	"github.com/SENERGY-Platform/reporting-service/pkg/config"
+)
+
+const (
+	BackendMailpit = "mailpit"
+	BackendSMTP    = "smtp"
+)
+
+// Mailer delivers emails.
+type Mailer interface {
+	Send(message Message) error
+}
+
+type Address struct {
+	Name  string
+	Email string
+}
+
+type Attachment struct {
+	Filename    string
+	ContentType string
+	Content     []byte
+}
+
+// Message is an email with optional text and HTML bodies. Bcc recipients are not visible to other recipients.
+type Message struct {
+	From        Address
+	To          []Address
+	Cc          []Address
+	Bcc         []string
+	ReplyTo     []Address
+	Subject     string
+	Text        string
+	HTML        string
+	Attachments []Attachment
+}
+
+// Recipients returns the email addresses of all recipients.
+func (m Message) Recipients() (recipients []string) {
+	for _, address := range append(m.To, m.Cc...) {
+		recipients = append(recipients, address.Email)
+	}
+	return append(recipients, m.Bcc...)
+}
+
+// New creates the mailer selected by the backend of the config.
+func New(cfg config.MailConfig) (Mailer, error) {
+	switch cfg.Backend {
+	case BackendMailpit, "":
+		return NewMailpit(cfg.MailpitUrl), nil
+	case BackendSMTP:
+		return NewSMTP(cfg.SMTP)
+	default:
+		return nil, fmt.Errorf("unknown mail backend %q", cfg.Backend)
+	}
+}
@@ -0,0 +1,61 @@
+/*
+ * Copyright 2025 InfAI (CC SES)
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package mailer
+
+import (
+	"encoding/base64"
+
+	"github.com/SENERGY-Platform/reporting-service/lib"
+)
+
+// Mailpit sends emails with the HTTP API of a Mailpit relay.
+type Mailpit struct {
+	Url string
+}
+
+func NewMailpit(url string) *Mailpit {
+	return &Mailpit{Url: url}
+}
+
+func (m *Mailpit) Send(message Message) (err error) {
+	request := lib.SendRequest{
+		Bcc:     message.Bcc,
+		Subject: message.Subject,
+		Text:    message.Text,
+		HTML:    message.HTML,
+	}
+	request.From.Name = message.From.Name
+	request.From.Email = message.From.Email
+	for _, address := range message.To {
+		request.To = append(request.To, struct{ Name, Email string }{Name: address.Name, Email: address.Email})
+	}
+	for _, address := range message.Cc {
+		request.Cc = append(request.Cc, struct{ Name, Email string }{Name: address.Name, Email: address.Email})
+	}
+	for _, address := range message.ReplyTo {
+		request.ReplyTo = append(request.ReplyTo, struct{ Name, Email string }{Name: address.Name, Email: address.Email})
+	}
+	for _, attachment := range message.Attachments {
+		request.Attachments = append(request.Attachments, struct{ Content, Filename, ContentType, ContentID string }{
+			Content:     base64.StdEncoding.EncodeToString(attachment.Content),
+			Filename:    attachment.Filename,
+			ContentType: attachment.ContentType,
+		})
+	}
+	_, err = request.Send(m.Url)
+	return
+}
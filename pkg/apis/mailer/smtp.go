@@ -0,0 +1,268 @@
+/*
+ * Copyright 2025 InfAI (CC SES)
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package mailer
+
+import (
+	"bytes"
+	"crypto/rand"
+	"crypto/tls"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"mime"
+	"mime/multipart"
+	"mime/quotedprintable"
+	"net"
+	"net/mail"
+	"net/smtp"
+	"net/textproto"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/SENERGY-Platform/reporting-service/pkg/config"
+)
+
+const (
+	TLSModeNone     = "none"
+	TLSModeStartTLS = "starttls"
+	TLSModeImplicit = "tls"
+)
+
+// SMTP sends emails directly to an SMTP server.
+type SMTP struct {
+	Host      string
+	Port      int
+	Username  string
+	Password  string
+	TLSMode   string
+	Timeout   time.Duration
+	TLSConfig *tls.Config
+}
+
+func NewSMTP(cfg config.SMTPConfig) (*SMTP, error) {
+	switch cfg.TLSMode {
+	case TLSModeNone, TLSModeStartTLS, TLSModeImplicit:
+	default:
+		return nil, fmt.Errorf("unknown smtp tls mode %q", cfg.TLSMode)
+	}
+	if cfg.Host == "" {
+		return nil, errors.New("missing smtp host")
+	}
+	return &SMTP{
+		Host:      cfg.Host,
+		Port:      cfg.Port,
+		Username:  cfg.Username,
+		Password:  cfg.Password.Value(),
+		TLSMode:   cfg.TLSMode,
+		Timeout:   cfg.Timeout,
+		TLSConfig: &tls.Config{ServerName: cfg.Host, InsecureSkipVerify: cfg.InsecureSkipVerify},
+	}, nil
+}
+
+func (s *SMTP) Send(message Message) (err error) {
+	recipients := message.Recipients()
+	if len(recipients) == 0 {
+		return errors.New("message without recipients")
+	}
+	body, err := buildMIME(message)
+	if err != nil {
+		return
+	}
+	client, err := s.connect()
+	if err != nil {
+		return
+	}
+	defer client.Close()
+	if s.Username != "" {
+		if err = client.Auth(smtp.PlainAuth("", s.Username, s.Password, s.Host)); err != nil {
+			return fmt.Errorf("smtp auth: %w", err)
+		}
+	}
+	if err = client.Mail(message.From.Email); err != nil {
+		return
+	}
+	for _, recipient := range recipients {
+		if err = client.Rcpt(recipient); err != nil {
+			return fmt.Errorf("smtp recipient %s: %w", recipient, err)
+		}
+	}
+	w, err := client.Data()
+	if err != nil {
+		return
+	}
+	if _, err = w.Write(body); err != nil {
+		return
+	}
+	if err = w.Close(); err != nil {
+		return
+	}
+	return client.Quit()
+}
+
+func (s *SMTP) connect() (client *smtp.Client, err error) {
+	address := net.JoinHostPort(s.Host, strconv.Itoa(s.Port))
+	dialer := &net.Dialer{Timeout: s.Timeout}
+	var conn net.Conn
+	if s.TLSMode == TLSModeImplicit {
+		conn, err = tls.DialWithDialer(dialer, "tcp", address, s.TLSConfig)
+	} else {
+		conn, err = dialer.Dial("tcp", address)
+	}
+	if err != nil {
+		return
+	}
+	if s.Timeout > 0 {
+		_ = conn.SetDeadline(time.Now().Add(s.Timeout))
+	}
+	client, err = smtp.NewClient(conn, s.Host)
+	if err != nil {
+		_ = conn.Close()
+		return
+	}
+	if s.TLSMode == TLSModeStartTLS {
+		if ok, _ := client.Extension("STARTTLS"); !ok {
+			_ = client.Close()
+			return nil, errors.New("smtp server does not support STARTTLS")
+		}
+		if err = client.StartTLS(s.TLSConfig); err != nil {
+			_ = client.Close()
+			return nil, err
+		}
+	}
+	return
+}
+
+// buildMIME creates a multipart/mixed message with the text and HTML bodies as multipart/alternative and the attachments.
+func buildMIME(message Message) (result []byte, err error) {
+	buf := &bytes.Buffer{}
+	header := func(key string, value string) {
+		buf.WriteString(key + ": " + value + "\r\n")
+	}
+	header("From", formatAddress(message.From))
+	if len(message.To) > 0 {
+		header("To", formatAddresses(message.To))
+	}
+	if len(message.Cc) > 0 {
+		header("Cc", formatAddresses(message.Cc))
+	}
+	if len(message.ReplyTo) > 0 {
+		header("Reply-To", formatAddresses(message.ReplyTo))
+	}
+	header("Subject", mime.QEncoding.Encode("utf-8", message.Subject))
+	header("Date", time.Now().Format(time.RFC1123Z))
+	header("Message-ID", messageId(message.From.Email))
+	header("MIME-Version", "1.0")
+
+	mixed := multipart.NewWriter(buf)
+	header("Content-Type", "multipart/mixed; boundary="+mixed.Boundary())
+	buf.WriteString("\r\n")
+
+	alternativeBuf := &bytes.Buffer{}
+	alternative := multipart.NewWriter(alternativeBuf)
+	if message.Text != "" || message.HTML == "" {
+		if err = writeQuotedPrintable(alternative, "text/plain; charset=utf-8", message.Text); err != nil {
+			return
+		}
+	}
+	if message.HTML != "" {
+		if err = writeQuotedPrintable(alternative, "text/html; charset=utf-8", message.HTML); err != nil {
+			return
+		}
+	}
+	if err = alternative.Close(); err != nil {
+		return
+	}
+	part, err := mixed.CreatePart(textproto.MIMEHeader{"Content-Type": {"multipart/alternative; boundary=" + alternative.Boundary()}})
+	if err != nil {
+		return
+	}
+	if _, err = part.Write(alternativeBuf.Bytes()); err != nil {
+		return
+	}
+
+	for _, attachment := range message.Attachments {
+		contentType := attachment.ContentType
+		if contentType == "" {
+			contentType = "application/octet-stream"
+		}
+		part, err = mixed.CreatePart(textproto.MIMEHeader{
+			"Content-Type":              {contentType},
+			"Content-Transfer-Encoding": {"base64"},
+			"Content-Disposition":       {mime.FormatMediaType("attachment", map[string]string{"filename": attachment.Filename})},
+		})
+		if err != nil {
+			return
+		}
+		if err = writeBase64Lines(part, attachment.Content); err != nil {
+			return
+		}
+	}
+	if err = mixed.Close(); err != nil {
+		return
+	}
+	return buf.Bytes(), nil
+}
+
+func writeQuotedPrintable(w *multipart.Writer, contentType string, content string) (err error) {
+	part, err := w.CreatePart(textproto.MIMEHeader{
+		"Content-Type":              {contentType},
+		"Content-Transfer-Encoding": {"quoted-printable"},
+	})
+	if err != nil {
+		return
+	}
+	qp := quotedprintable.NewWriter(part)
+	if _, err = qp.Write([]byte(content)); err != nil {
+		return
+	}
+	return qp.Close()
+}
+
+// writeBase64Lines writes base64 encoded content in lines of 76 characters as required by RFC 2045.
+func writeBase64Lines(w interface{ Write([]byte) (int, error) }, content []byte) (err error) {
+	encoded := base64.StdEncoding.EncodeToString(content)
+	for start := 0; start < len(encoded); start += 76 {
+		if _, err = w.Write([]byte(encoded[start:min(start+76, len(encoded))] + "\r\n")); err != nil {
+			return
+		}
+	}
+	return
+}
+
+func formatAddress(address Address) string {
+	return (&mail.Address{Name: address.Name, Address: address.Email}).String()
+}
+
+func formatAddresses(addresses []Address) string {
+	formatted := make([]string, 0, len(addresses))
+	for _, address := range addresses {
+		formatted = append(formatted, formatAddress(address))
+	}
+	return strings.Join(formatted, ", ")
+}
+
+func messageId(from string) string {
+	b := make([]byte, 16)
+	_, _ = rand.Read(b)
+	domain := "localhost"
+	if index := strings.LastIndex(from, "@"); index >= 0 {
+		domain = from[index+1:]
+	}
+	return "<" + hex.EncodeToString(b) + "@" + domain + ">"
+}
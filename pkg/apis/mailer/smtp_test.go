@@ -0,0 +1,251 @@
+/*
+ * Copyright 2025 InfAI (CC SES)
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package mailer
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/base64"
+	"io"
+	"mime"
+	"mime/multipart"
+	"net"
+	"net/mail"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	sb_config_types "github.com/SENERGY-Platform/go-service-base/config-hdl/types"
+	"github.com/SENERGY-Platform/reporting-service/pkg/config"
+)
+
+// fakeSMTPSession is what the fake server received during one session.
+type fakeSMTPSession struct {
+	auth       string
+	from       string
+	recipients []string
+	data       []byte
+}
+
+// startFakeSMTP starts a minimal SMTP server, which accepts one session and reports it on the returned channel.
+func startFakeSMTP(t *testing.T) (host string, port int, sessions chan fakeSMTPSession) {
+	t.Helper()
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { _ = listener.Close() })
+	sessions = make(chan fakeSMTPSession, 1)
+	go func() {
+		conn, err := listener.Accept()
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+		_ = conn.SetDeadline(time.Now().Add(10 * time.Second))
+		reader := bufio.NewReader(conn)
+		reply := func(line string) { _, _ = io.WriteString(conn, line+"\r\n") }
+		var session fakeSMTPSession
+		reply("220 localhost fake smtp")
+		for {
+			line, err := reader.ReadString('\n')
+			if err != nil {
+				return
+			}
+			line = strings.TrimRight(line, "\r\n")
+			command := strings.ToUpper(strings.SplitN(line, " ", 2)[0])
+			switch {
+			case command == "EHLO":
+				reply("250-localhost")
+				reply("250 AUTH PLAIN")
+			case command == "AUTH":
+				session.auth = line
+				reply("235 authenticated")
+			case strings.HasPrefix(strings.ToUpper(line), "MAIL FROM:"):
+				session.from = strings.Trim(line[len("MAIL FROM:"):], "<>")
+				reply("250 ok")
+			case strings.HasPrefix(strings.ToUpper(line), "RCPT TO:"):
+				session.recipients = append(session.recipients, strings.Trim(line[len("RCPT TO:"):], "<>"))
+				reply("250 ok")
+			case command == "DATA":
+				reply("354 send data")
+				var data bytes.Buffer
+				for {
+					dataLine, err := reader.ReadString('\n')
+					if err != nil {
+						return
+					}
+					if dataLine == ".\r\n" {
+						break
+					}
+					data.WriteString(strings.TrimPrefix(dataLine, "."))
+				}
+				session.data = data.Bytes()
+				reply("250 queued")
+			case command == "QUIT":
+				reply("221 bye")
+				sessions <- session
+				return
+			default:
+				reply("500 unknown command")
+			}
+		}
+	}()
+	address := listener.Addr().(*net.TCPAddr)
+	return address.IP.String(), address.Port, sessions
+}
+
+func TestSMTPSend(t *testing.T) {
+	host, port, sessions := startFakeSMTP(t)
+	sender, err := NewSMTP(config.SMTPConfig{
+		Host:     host,
+		Port:     port,
+		Username: "user",
+		Password: sb_config_types.Secret("password"),
+		TLSMode:  TLSModeNone,
+		Timeout:  5 * time.Second,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	attachment := bytes.Repeat([]byte("%PDF report content "), 20)
+	err = sender.Send(Message{
+		From:        Address{Name: "Reporting", Email: "reporting@example.com"},
+		To:          []Address{{Name: "Jane Doe", Email: "jane@example.com"}},
+		Cc:          []Address{{Email: "cc@example.com"}},
+		Bcc:         []string{"bcc@example.com"},
+		ReplyTo:     []Address{{Email: "support@example.com"}},
+		Subject:     "Monthly report – März",
+		Text:        "Report attached",
+		HTML:        "<p>Report attached</p>",
+		Attachments: []Attachment{{Filename: "report.pdf", ContentType: "application/pdf", Content: attachment}},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	var session fakeSMTPSession
+	select {
+	case session = <-sessions:
+	case <-time.After(5 * time.Second):
+		t.Fatal("fake smtp server did not receive the session")
+	}
+
+	// envelope
+	wantAuth := "AUTH PLAIN " + base64.StdEncoding.EncodeToString([]byte("\x00user\x00password"))
+	if session.auth != wantAuth {
+		t.Errorf("auth = %q, want %q", session.auth, wantAuth)
+	}
+	if session.from != "reporting@example.com" {
+		t.Errorf("envelope from = %q", session.from)
+	}
+	if !slices.Equal(session.recipients, []string{"jane@example.com", "cc@example.com", "bcc@example.com"}) {
+		t.Errorf("envelope recipients = %v", session.recipients)
+	}
+
+	// headers
+	msg, err := mail.ReadMessage(bytes.NewReader(session.data))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for key, want := range map[string]string{
+		"From":     `"Reporting" <reporting@example.com>`,
+		"To":       `"Jane Doe" <jane@example.com>`,
+		"Cc":       `<cc@example.com>`,
+		"Reply-To": `<support@example.com>`,
+	} {
+		if got := msg.Header.Get(key); got != want {
+			t.Errorf("header %s = %q, want %q", key, got, want)
+		}
+	}
+	if msg.Header.Get("Bcc") != "" || bytes.Contains(session.data, []byte("bcc@example.com")) {
+		t.Error("bcc recipient is visible in the message")
+	}
+	subject, err := new(mime.WordDecoder).DecodeHeader(msg.Header.Get("Subject"))
+	if err != nil || subject != "Monthly report – März" {
+		t.Errorf("subject = %q, %v", subject, err)
+	}
+	if !strings.HasSuffix(msg.Header.Get("Message-ID"), "@example.com>") {
+		t.Errorf("message id = %q", msg.Header.Get("Message-ID"))
+	}
+
+	// bodies and attachment
+	mediaType, params, err := mime.ParseMediaType(msg.Header.Get("Content-Type"))
+	if err != nil || mediaType != "multipart/mixed" {
+		t.Fatalf("content type = %q, %v", mediaType, err)
+	}
+	parts := multipart.NewReader(msg.Body, params["boundary"])
+	alternative, err := parts.NextPart()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.HasPrefix(alternative.Header.Get("Content-Type"), "multipart/alternative") {
+		t.Errorf("first part = %q, want multipart/alternative", alternative.Header.Get("Content-Type"))
+	}
+	file, err := parts.NextPart()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if file.FileName() != "report.pdf" || file.Header.Get("Content-Type") != "application/pdf" {
+		t.Errorf("attachment = %q of type %q", file.FileName(), file.Header.Get("Content-Type"))
+	}
+	encoded, err := io.ReadAll(file)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, line := range strings.Split(strings.TrimSpace(string(encoded)), "\r\n") {
+		if len(line) > 76 {
+			t.Errorf("base64 line of %d characters", len(line))
+		}
+	}
+	content, err := base64.StdEncoding.DecodeString(strings.ReplaceAll(string(encoded), "\r\n", ""))
+	if err != nil || !bytes.Equal(content, attachment) {
+		t.Errorf("attachment content differs, %v", err)
+	}
+	if _, err = parts.NextPart(); err != io.EOF {
+		t.Errorf("unexpected part after attachment, %v", err)
+	}
+}
+
+func TestNewSMTPInvalidConfig(t *testing.T) {
+	for name, cfg := range map[string]config.SMTPConfig{
+		"missing host":     {Port: 25, TLSMode: TLSModeNone},
+		"unknown tls mode": {Host: "localhost", Port: 25, TLSMode: "ssl"},
+	} {
+		if _, err := NewSMTP(cfg); err == nil {
+			t.Errorf("%s: expected error", name)
+		}
+	}
+	if _, err := New(config.MailConfig{Backend: "sendmail"}); err == nil {
+		t.Error("unknown backend: expected error")
+	}
+	if _, err := New(config.MailConfig{Backend: BackendSMTP, SMTP: config.SMTPConfig{Host: "localhost", Port: 25, TLSMode: TLSModeStartTLS}}); err != nil {
+		t.Errorf("valid config: %v", err)
+	}
+}
+
+func TestSMTPStartTLSRequired(t *testing.T) {
+	host, port, _ := startFakeSMTP(t)
+	sender, err := NewSMTP(config.SMTPConfig{Host: host, Port: port, TLSMode: TLSModeStartTLS, Timeout: 5 * time.Second})
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = sender.Send(Message{From: Address{Email: "reporting@example.com"}, To: []Address{{Email: "jane@example.com"}}})
+	if err == nil || !strings.Contains(err.Error(), "STARTTLS") {
+		t.Errorf("expected missing STARTTLS error, got %v", err)
+	}
+}
@@ -0,0 +1,174 @@
+/*
+ * Copyright 2025 InfAI (CC SES)
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package s3
+
+import (
+	"bytes"
+	"cmp"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"slices"
+	"strings"
+	"time"
+)
+
+// Client uploads objects to an S3-compatible object storage, authenticated with AWS signature version 4.
+type Client struct {
+	Endpoint   string // e.g. https://s3.eu-central-1.amazonaws.com or http://localhost:9000
+	Region     string
+	Bucket     string
+	AccessKey  string
+	SecretKey  string
+	PathStyle  bool // address the bucket in the path instead of the host name, required by most S3-compatible servers
+	HttpClient *http.Client
+}
+
+func NewClient(endpoint string, region string, bucket string, accessKey string, secretKey string, pathStyle bool, timeout time.Duration) *Client {
+	return &Client{
+		Endpoint:   strings.TrimSuffix(endpoint, "/"),
+		Region:     region,
+		Bucket:     bucket,
+		AccessKey:  accessKey,
+		SecretKey:  secretKey,
+		PathStyle:  pathStyle,
+		HttpClient: &http.Client{Timeout: timeout},
+	}
+}
+
+// ObjectUrl returns the url of the object with the given key.
+func (c *Client) ObjectUrl(key string) (string, error) {
+	endpoint, err := url.Parse(c.Endpoint)
+	if err != nil {
+		return "", err
+	}
+	path := "/" + escapePath(key)
+	if c.PathStyle {
+		path = "/" + c.Bucket + path
+	} else {
+		endpoint.Host = c.Bucket + "." + endpoint.Host
+	}
+	return endpoint.Scheme + "://" + endpoint.Host + path, nil
+}
+
+// PutObject uploads content with the given key.
+func (c *Client) PutObject(ctx context.Context, key string, content []byte, contentType string) (err error) {
+	objectUrl, err := c.ObjectUrl(key)
+	if err != nil {
+		return
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPut, objectUrl, bytes.NewReader(content))
+	if err != nil {
+		return
+	}
+	if contentType != "" {
+		req.Header.Set("Content-Type", contentType)
+	}
+	c.sign(req, content, time.Now().UTC())
+	resp, err := c.HttpClient.Do(req)
+	if err != nil {
+		return
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode > 299 {
+		body, _ := io.ReadAll(resp.Body)
+		return fmt.Errorf("unexpected statuscode %v : %v", resp.StatusCode, string(body))
+	}
+	return
+}
+
+func (c *Client) sign(req *http.Request, payload []byte, now time.Time) {
+	amzDate := now.Format("20060102T150405Z")
+	date := now.Format("20060102")
+	payloadHash := sha256Hex(payload)
+	req.Header.Set("x-amz-date", amzDate)
+	req.Header.Set("x-amz-content-sha256", payloadHash)
+
+	signedHeaders := "host;x-amz-content-sha256;x-amz-date"
+	canonicalHeaders := "host:" + req.URL.Host + "\n" +
+		"x-amz-content-sha256:" + payloadHash + "\n" +
+		"x-amz-date:" + amzDate + "\n"
+	canonicalRequest := strings.Join([]string{
+		req.Method,
+		req.URL.EscapedPath(),
+		canonicalQuery(req.URL.Query()),
+		canonicalHeaders,
+		signedHeaders,
+		payloadHash,
+	}, "\n")
+	scope := date + "/" + c.Region + "/s3/aws4_request"
+	stringToSign := "AWS4-HMAC-SHA256\n" + amzDate + "\n" + scope + "\n" + sha256Hex([]byte(canonicalRequest))
+
+	key := hmacSHA256([]byte("AWS4"+c.SecretKey), date)
+	key = hmacSHA256(key, c.Region)
+	key = hmacSHA256(key, "s3")
+	key = hmacSHA256(key, "aws4_request")
+	signature := hex.EncodeToString(hmacSHA256(key, stringToSign))
+
+	req.Header.Set("Authorization", "AWS4-HMAC-SHA256 Credential="+c.AccessKey+"/"+scope+", SignedHeaders="+signedHeaders+", Signature="+signature)
+}
+
+// canonicalQuery sorts and escapes the query parameters as required by the canonical request of signature version 4.
+func canonicalQuery(query url.Values) string {
+	var pairs [][2]string
+	for key, values := range query {
+		for _, value := range values {
+			pairs = append(pairs, [2]string{uriEncode(key, true), uriEncode(value, true)})
+		}
+	}
+	slices.SortFunc(pairs, func(a, b [2]string) int {
+		return cmp.Or(strings.Compare(a[0], b[0]), strings.Compare(a[1], b[1]))
+	})
+	encoded := make([]string, 0, len(pairs))
+	for _, pair := range pairs {
+		encoded = append(encoded, pair[0]+"="+pair[1])
+	}
+	return strings.Join(encoded, "&")
+}
+
+// escapePath escapes every segment of an object key as required by the canonical request of signature version 4.
+func escapePath(key string) string {
+	return uriEncode(key, false)
+}
+
+func uriEncode(value string, encodeSlash bool) string {
+	var b strings.Builder
+	for _, c := range []byte(value) {
+		if ('A' <= c && c <= 'Z') || ('a' <= c && c <= 'z') || ('0' <= c && c <= '9') || strings.IndexByte("-_.~", c) >= 0 || (c == '/' && !encodeSlash) {
+			b.WriteByte(c)
+		} else {
+			fmt.Fprintf(&b, "%%%02X", c)
+		}
+	}
+	return b.String()
+}
+
+func sha256Hex(data []byte) string {
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
+
+func hmacSHA256(key []byte, data string) []byte {
+	mac := hmac.New(sha256.New, key)
+	mac.Write([]byte(data))
+	return mac.Sum(nil)
+}
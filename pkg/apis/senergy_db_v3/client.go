@@ -21,6 +21,8 @@ import (
 	"errors"
 	"fmt"
 	"net/http"
+	"strconv"
+	"time"
 
 	"github.com/SENERGY-Platform/reporting-service/lib"
 	timescaleModels "github.com/SENERGY-Platform/timescale-wrapper/pkg/model"
@@ -58,9 +60,23 @@ func (s *Client) Query(authTokenString string, query timescaleModels.QueriesRequ
 	if err != nil {
 		return data, errors.New("senergy_db_v3.client - response unmarshal error: " + err.Error())
 	}
+	var keys []string
+	var location *time.Location
+	if queryOptions.ResultObject != nil && *queryOptions.ResultObject == "object" {
+		keys = columnKeys(query, queryOptions)
+		location = time.UTC
+		if queryOptions.TimeLocation != nil {
+			location, err = time.LoadLocation(*queryOptions.TimeLocation)
+			if err != nil {
+				return data, errors.New("senergy_db_v3.client - invalid time location: " + err.Error())
+			}
+		}
+	}
 	for _, value := range resp[0].Data[0] {
 		if queryOptions.ResultObject != nil {
 			switch *queryOptions.ResultObject {
+			case "object":
+				data = append(data, rowObject(value, keys, queryOptions, location))
 			case "key":
 				data = append(data, value[*queryOptions.ResultKey])
 			case "array":
@@ -74,3 +90,48 @@ func (s *Client) Query(authTokenString string, query timescaleModels.QueriesRequ
 	}
 	return data, err
 }
+
+// columnKeys returns the object keys of the value columns, taken from the query options or the column names of the query.
+// Duplicate names are suffixed with their column index.
+func columnKeys(query timescaleModels.QueriesRequestElement, queryOptions lib.QueryOptions) (keys []string) {
+	used := map[string]bool{"time": true}
+	for i, column := range query.Columns {
+		key := column.Name
+		if i < len(queryOptions.ColumnNames) && queryOptions.ColumnNames[i] != "" {
+			key = queryOptions.ColumnNames[i]
+		}
+		if used[key] {
+			key = key + "_" + strconv.Itoa(i+1)
+		}
+		used[key] = true
+		keys = append(keys, key)
+	}
+	return
+}
+
+// rowObject converts a result row of the form [time, column1, column2, ...] into an object keyed by column name.
+func rowObject(row []interface{}, keys []string, queryOptions lib.QueryOptions, location *time.Location) (object map[string]interface{}) {
+	object = make(map[string]interface{}, len(row))
+	if len(row) == 0 {
+		return
+	}
+	object["time"] = row[0]
+	if ts, ok := row[0].(string); ok {
+		t, err := time.Parse(time.RFC3339Nano, ts)
+		if err == nil {
+			layout := time.RFC3339
+			if queryOptions.TimeLayout != nil {
+				layout = *queryOptions.TimeLayout
+			}
+			object["time"] = t.In(location).Format(layout)
+		}
+	}
+	for i, value := range row[1:] {
+		key := strconv.Itoa(i + 1)
+		if i < len(keys) {
+			key = keys[i]
+		}
+		object[key] = value
+	}
+	return
+}
@@ -17,7 +17,10 @@
 package config
 
 import (
+	"time"
+
 	sb_config_hdl "github.com/SENERGY-Platform/go-service-base/config-hdl"
+	sb_config_types "github.com/SENERGY-Platform/go-service-base/config-hdl/types"
 )
 
 type LoggerConfig struct {
@@ -34,6 +37,18 @@ type SNRGYConfig struct {
 	Port int64  `json:"port" env_var:"SENERGY_DB_PORT"`
 }
 
+type DevicesConfig struct {
+	PageSize       int           `json:"page_size" env_var:"DEVICES_PAGE_SIZE"`
+	MaxDevices     int           `json:"max_devices" env_var:"DEVICES_MAX"`
+	RequestTimeout time.Duration `json:"request_timeout" env_var:"DEVICES_REQUEST_TIMEOUT"`
+}
+
+type ConnectionLogConfig struct {
+	ChunkSize      int           `json:"chunk_size" env_var:"CONNECTION_LOG_CHUNK_SIZE"`
+	MaxConcurrency int           `json:"max_concurrency" env_var:"CONNECTION_LOG_MAX_CONCURRENCY"`
+	RequestTimeout time.Duration `json:"request_timeout" env_var:"CONNECTION_LOG_REQUEST_TIMEOUT"`
+}
+
 type KeycloakConfig struct {
 	Url          string `json:"url" env_var:"KEYCLOAK_URL"`
 	ClientId     string `json:"client_id" env_var:"KEYCLOAK_CLIENT_ID"`
@@ -41,23 +56,86 @@ type KeycloakConfig struct {
 }
 
 type MailConfig struct {
-	MailpitUrl string `json:"mailpit_url" env_var:"MAILPIT_URL"`
-	From       string `json:"from" env_var:"EMAIL_FROM"`
-	Subject    string `json:"subject" env_var:"EMAIL_SUBJECT"`
-	Text       string `json:"text" env_var:"EMAIL_TEXT"`
+	Backend    string     `json:"backend" env_var:"MAIL_BACKEND"` // mailpit or smtp
+	MailpitUrl string     `json:"mailpit_url" env_var:"MAILPIT_URL"`
+	SMTP       SMTPConfig `json:"smtp"`
+	From       string     `json:"from" env_var:"EMAIL_FROM"`
+	Subject    string     `json:"subject" env_var:"EMAIL_SUBJECT"`
+	Text       string     `json:"text" env_var:"EMAIL_TEXT"`
+	// MaxRecipients limits the recipients per email, larger recipient lists are split into several emails.
+	MaxRecipients int `json:"max_recipients" env_var:"MAIL_MAX_RECIPIENTS"`
+	// MaxAttachmentSize limits the size of attachments in bytes, larger files are sent as download link. 0 disables the limit.
+	MaxAttachmentSize int64 `json:"max_attachment_size" env_var:"MAIL_MAX_ATTACHMENT_SIZE"`
+}
+
+type SMTPConfig struct {
+	Host               string                 `json:"host" env_var:"SMTP_HOST"`
+	Port               int                    `json:"port" env_var:"SMTP_PORT"`
+	Username           string                 `json:"username" env_var:"SMTP_USERNAME"`
+	Password           sb_config_types.Secret `json:"password" env_var:"SMTP_PASSWORD"`
+	TLSMode            string                 `json:"tls_mode" env_var:"SMTP_TLS_MODE"` // none, starttls or tls
+	InsecureSkipVerify bool                   `json:"insecure_skip_verify" env_var:"SMTP_INSECURE_SKIP_VERIFY"`
+	Timeout            time.Duration          `json:"timeout" env_var:"SMTP_TIMEOUT"`
+}
+
+type ShareConfig struct {
+	Secret     sb_config_types.Secret `json:"secret" env_var:"SHARE_SECRET"`
+	BaseUrl    string                 `json:"base_url" env_var:"SHARE_BASE_URL"` // public url of the service including the url prefix
+	DefaultTTL time.Duration          `json:"default_ttl" env_var:"SHARE_DEFAULT_TTL"`
+	MaxTTL     time.Duration          `json:"max_ttl" env_var:"SHARE_MAX_TTL"`
+}
+
+type WebhookConfig struct {
+	Timeout   time.Duration `json:"timeout" env_var:"WEBHOOK_TIMEOUT"`
+	Retries   int           `json:"retries" env_var:"WEBHOOK_RETRIES"`
+	RetryWait time.Duration `json:"retry_wait" env_var:"WEBHOOK_RETRY_WAIT"`
+	LinkTTL   time.Duration `json:"link_ttl" env_var:"WEBHOOK_LINK_TTL"` // lifetime of download links in notifications
+}
+
+type DeliveryConfig struct {
+	FilesystemRoot string        `json:"filesystem_root" env_var:"DELIVERY_FILESYSTEM_ROOT"` // filesystem targets are disabled, if empty
+	Timeout        time.Duration `json:"timeout" env_var:"DELIVERY_TIMEOUT"`
+	AllowedHosts   string        `json:"allowed_hosts" env_var:"DELIVERY_ALLOWED_HOSTS"` // comma separated webhook and s3 hosts, which may resolve to private addresses
+}
+
+type KafkaConfig struct {
+	Bootstrap      string        `json:"bootstrap" env_var:"KAFKA_BOOTSTRAP"` // comma separated brokers, events are disabled, if empty
+	TopicFiles     string        `json:"topic_files" env_var:"KAFKA_TOPIC_FILES"`
+	TopicRuns      string        `json:"topic_runs" env_var:"KAFKA_TOPIC_RUNS"`
+	TopicReports   string        `json:"topic_reports" env_var:"KAFKA_TOPIC_REPORTS"`
+	OutboxInterval time.Duration `json:"outbox_interval" env_var:"KAFKA_OUTBOX_INTERVAL"`
+	OutboxBatch    int           `json:"outbox_batch" env_var:"KAFKA_OUTBOX_BATCH"`
+	OutboxAttempts int           `json:"outbox_attempts" env_var:"KAFKA_OUTBOX_MAX_ATTEMPTS"` // events failing as often are no longer published
+	Timeout        time.Duration `json:"timeout" env_var:"KAFKA_TIMEOUT"`
+}
+
+type TriggerConfig struct {
+	GroupId        string        `json:"group_id" env_var:"TRIGGER_GROUP_ID"`
+	DeviceLogTopic string        `json:"device_log_topic" env_var:"TRIGGER_DEVICE_LOG_TOPIC"`
+	Interval       time.Duration `json:"interval" env_var:"TRIGGER_INTERVAL"` // minimal interval between triggered runs of a report
+	Refresh        time.Duration `json:"refresh" env_var:"TRIGGER_REFRESH"`   // interval to update the consumed topics
+	Concurrency    int           `json:"concurrency" env_var:"TRIGGER_CONCURRENCY"`
+	Topics         string        `json:"topics" env_var:"TRIGGER_TOPICS"` // comma separated topics of kafka triggers, disabled if empty
 }
 
 type Config struct {
-	Logger                  LoggerConfig   `json:"logger" env_var:"LOGGER_CONFIG"`
-	URLPrefix               string         `json:"url_prefix" env_var:"URL_PREFIX"`
-	ServerPort              int            `json:"server_port" env_var:"SERVER_PORT"`
-	Debug                   bool           `json:"debug" env_var:"DEBUG"`
-	JSReport                JSReportConfig `json:"jsreport"`
-	SNRGY                   SNRGYConfig    `json:"snrgy"`
-	Keycloak                KeycloakConfig `json:"keycloak"`
-	Mail                    MailConfig     `json:"mail"`
-	SchedulerTickerDuration string         `json:"scheduler_ticker_duration" env_var:"SCHEDULER_TICKER_DURATION"`
-	MongoUrl                string         `json:"mongo_url" env_var:"MONGODB_URI"`
+	Logger                  LoggerConfig        `json:"logger" env_var:"LOGGER_CONFIG"`
+	URLPrefix               string              `json:"url_prefix" env_var:"URL_PREFIX"`
+	ServerPort              int                 `json:"server_port" env_var:"SERVER_PORT"`
+	Debug                   bool                `json:"debug" env_var:"DEBUG"`
+	JSReport                JSReportConfig      `json:"jsreport"`
+	SNRGY                   SNRGYConfig         `json:"snrgy"`
+	Devices                 DevicesConfig       `json:"devices"`
+	ConnectionLog           ConnectionLogConfig `json:"connection_log"`
+	Keycloak                KeycloakConfig      `json:"keycloak"`
+	Mail                    MailConfig          `json:"mail"`
+	Share                   ShareConfig         `json:"share"`
+	Webhook                 WebhookConfig       `json:"webhook"`
+	Delivery                DeliveryConfig      `json:"delivery"`
+	Kafka                   KafkaConfig         `json:"kafka"`
+	Trigger                 TriggerConfig       `json:"trigger"`
+	SchedulerTickerDuration string              `json:"scheduler_ticker_duration" env_var:"SCHEDULER_TICKER_DURATION"`
+	MongoUrl                string              `json:"mongo_url" env_var:"MONGODB_URI"`
 }
 
 func New(path string) (*Config, error) {
@@ -72,16 +150,64 @@ func New(path string) (*Config, error) {
 			Url:  "http://localhost",
 			Port: 80,
 		},
+		Devices: DevicesConfig{
+			PageSize:       500,
+			MaxDevices:     10000,
+			RequestTimeout: 30 * time.Second,
+		},
+		ConnectionLog: ConnectionLogConfig{
+			ChunkSize:      200,
+			MaxConcurrency: 4,
+			RequestTimeout: 30 * time.Second,
+		},
 		Keycloak: KeycloakConfig{
 			Url:          "http://localhost",
 			ClientId:     "reporting-service",
 			ClientSecret: "reporting-service",
 		},
 		Mail: MailConfig{
+			Backend:    "mailpit",
 			MailpitUrl: "http://mailpit.notifier:8025",
-			From:       "reporting-service@localhost",
-			Subject:    "Report",
-			Text:       "Report attached to this email",
+			SMTP: SMTPConfig{
+				Port:    587,
+				TLSMode: "starttls",
+				Timeout: 30 * time.Second,
+			},
+			From:              "reporting-service@localhost",
+			Subject:           "Report",
+			Text:              "Report attached to this email",
+			MaxRecipients:     50,
+			MaxAttachmentSize: 10 << 20,
+		},
+		Share: ShareConfig{
+			BaseUrl:    "http://localhost:8080",
+			DefaultTTL: 7 * 24 * time.Hour,
+			MaxTTL:     90 * 24 * time.Hour,
+		},
+		Webhook: WebhookConfig{
+			Timeout:   10 * time.Second,
+			Retries:   3,
+			RetryWait: 5 * time.Second,
+			LinkTTL:   7 * 24 * time.Hour,
+		},
+		Delivery: DeliveryConfig{
+			Timeout: 60 * time.Second,
+		},
+		Kafka: KafkaConfig{
+			TopicFiles:     "reporting-files",
+			TopicRuns:      "reporting-runs",
+			TopicReports:   "reporting-reports",
+			OutboxInterval: 5 * time.Second,
+			OutboxBatch:    100,
+			OutboxAttempts: 20,
+			Timeout:        10 * time.Second,
+		},
+		Trigger: TriggerConfig{
+			GroupId:        "reporting-service",
+			DeviceLogTopic: "device_log",
+			Interval:       5 * time.Minute,
+			Refresh:        time.Minute,
+			Concurrency:    4,
 		},
 		SchedulerTickerDuration: "1m",
 		MongoUrl:                "mongodb://localhost:27017",
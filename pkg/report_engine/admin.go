@@ -0,0 +1,96 @@
+/*
+ * Copyright 2025 InfAI (CC SES)
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package report_engine
+
+import (
+	"errors"
+
+	"github.com/SENERGY-Platform/reporting-service/lib"
+	"github.com/SENERGY-Platform/service-commons/pkg/jwt"
+	"github.com/globalsign/mgo/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+// The admin methods operate on reports of all users. Callers have to make sure the requesting user is an admin.
+
+var ErrNotFound = errors.New("report not found")
+
+// AdminGetReportModel retrieves any report including its report files.
+func (r *Client) AdminGetReportModel(id string) (report lib.Report, err error) {
+	report, err = findReport(id)
+	return withoutSecrets(report), err
+}
+
+// findReport retrieves any report including its secrets. Returns ErrNotFound, if the report does not exist.
+func findReport(id string) (report lib.Report, err error) {
+	err = Reports().FindOne(CTX, bson.M{"_id": id}).Decode(&report)
+	if errors.Is(err, mongo.ErrNoDocuments) {
+		err = ErrNotFound
+	}
+	return
+}
+
+// AdminGetReportRuns lists the runs of any report, latest first.
+//
+// Parameters:
+// - id: The ID of the report.
+// - args: A map of query arguments, including limit and offset.
+//
+// Returns:
+// - runs: The runs of the report.
+// - err: ErrNotFound if the report does not exist, another error if the operation fails.
+func (r *Client) AdminGetReportRuns(id string, args map[string][]string) (runs []lib.ReportRun, err error) {
+	_, err = findReport(id)
+	if err != nil {
+		return
+	}
+	return findReportRuns(id, args)
+}
+
+// AdminRunReport creates a report file on behalf of the report owner and emails it to the receivers of the report.
+//
+// Parameters:
+// - id: The ID of the report to run.
+//
+// Returns:
+// - reportFileId: The ID of the created report file.
+// - err: An error if the operation fails.
+func (r *Client) AdminRunReport(id string) (reportFileId string, err error) {
+	report, err := findReport(id)
+	if err != nil {
+		return
+	}
+	return r.runReport(report)
+}
+
+// AdminDeleteReport deletes a report and its files on behalf of the report owner.
+func (r *Client) AdminDeleteReport(id string) (err error) {
+	report, err := findReport(id)
+	if err != nil {
+		return
+	}
+	token, _, err := jwt.ExchangeUserToken(
+		r.Config.Keycloak.Url,
+		r.Config.Keycloak.ClientId,
+		r.Config.Keycloak.ClientSecret,
+		report.UserId,
+	)
+	if err != nil {
+		return
+	}
+	return r.DeleteReport(id, token.Token, true)
+}
@@ -0,0 +1,81 @@
+/*
+ * Copyright 2025 InfAI (CC SES)
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package report_engine
+
+import (
+	"archive/zip"
+	"bytes"
+	"html"
+	"path"
+	"strings"
+	"time"
+
+	"github.com/SENERGY-Platform/reporting-service/lib"
+	"github.com/SENERGY-Platform/reporting-service/pkg/apis/mailer"
+)
+
+// emailAttachment prepares a report file for an email. The file is zipped, if the report requests it.
+// If it still exceeds the attachment size limit, no attachment is returned and the file has to be linked.
+func (r *Client) emailAttachment(report lib.Report, filename string, content []byte, contentType string) (attachment *mailer.Attachment, mode string, err error) {
+	attachment = &mailer.Attachment{Filename: filename, ContentType: contentType, Content: content}
+	mode = lib.AttachmentModeFile
+	if report.EmailZip {
+		attachment, err = zipAttachment(*attachment, time.Now())
+		if err != nil {
+			return nil, "", err
+		}
+		mode = lib.AttachmentModeZip
+	}
+	if limit := r.Config.Mail.MaxAttachmentSize; limit > 0 && int64(len(attachment.Content)) > limit {
+		return nil, lib.AttachmentModeLink, nil
+	}
+	return
+}
+
+// zipAttachment compresses the attachment into a zip archive containing the original file.
+func zipAttachment(attachment mailer.Attachment, modified time.Time) (*mailer.Attachment, error) {
+	var buf bytes.Buffer
+	archive := zip.NewWriter(&buf)
+	writer, err := archive.CreateHeader(&zip.FileHeader{Name: attachment.Filename, Method: zip.Deflate, Modified: modified})
+	if err != nil {
+		return nil, err
+	}
+	_, err = writer.Write(attachment.Content)
+	if err != nil {
+		return nil, err
+	}
+	err = archive.Close()
+	if err != nil {
+		return nil, err
+	}
+	return &mailer.Attachment{
+		Filename:    strings.TrimSuffix(attachment.Filename, path.Ext(attachment.Filename)) + ".zip",
+		ContentType: "application/zip",
+		Content:     buf.Bytes(),
+	}, nil
+}
+
+// appendDownloadLink adds the download link to the email, if the templates do not already contain it.
+func appendDownloadLink(content emailContent, link string) emailContent {
+	if !strings.Contains(content.Text, link) {
+		content.Text = strings.TrimSpace(content.Text + "\n\nDownload: " + link)
+	}
+	if content.HTML != "" && !strings.Contains(content.HTML, html.EscapeString(link)) {
+		content.HTML += `<p><a href="` + html.EscapeString(link) + `">Download</a></p>`
+	}
+	return content
+}
@@ -0,0 +1,206 @@
+/*
+ * Copyright 2025 InfAI (CC SES)
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package report_engine
+
+import (
+	"encoding/json"
+	"reflect"
+	"strconv"
+	"time"
+
+	"github.com/SENERGY-Platform/reporting-service/lib"
+	"github.com/SENERGY-Platform/service-commons/pkg/jwt"
+	"github.com/globalsign/mgo/bson"
+	"github.com/google/uuid"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+const (
+	AuditActionReportCreate      = "report.create"
+	AuditActionReportUpdate      = "report.update"
+	AuditActionReportPermissions = "report.permissions"
+	AuditActionReportRun         = "report.run"
+	AuditActionReportDelete      = "report.delete"
+	AuditActionReportRestore     = "report.restore"
+	AuditActionReportClone       = "report.clone"
+	AuditActionReportImport      = "report.import"
+	AuditActionReportBulk        = "report.bulk."
+	AuditActionFileDownload      = "file.download"
+	AuditActionFileDelete        = "file.delete"
+	AuditActionFileShare         = "file.share"
+	AuditActionFileShareRevoke   = "file.share.revoke"
+	AuditActionFileSharedGet     = "file.shared.download"
+)
+
+const (
+	// AuditActorScheduler is the actor of scheduled report runs.
+	AuditActorScheduler = "scheduler"
+	// AuditActorTrigger is the actor of report runs started by triggers.
+	AuditActorTrigger = "trigger"
+)
+
+// RecordAudit appends an entry to the audit log. Entries are never updated or deleted.
+// Webhook secrets and S3 secret keys are removed from the changes.
+func (r *Client) RecordAudit(entry lib.AuditEntry) (err error) {
+	auditWithoutSecrets(entry.Changes)
+	entry.Id = uuid.New().String()
+	entry.Timestamp = time.Now()
+	_, err = Audit().InsertOne(CTX, entry)
+	return
+}
+
+// AuditChanges compares the JSON representations of before and after and returns all changed top level fields.
+func AuditChanges(before interface{}, after interface{}) (changes map[string]lib.AuditChange) {
+	beforeMap, afterMap := toJSONMap(before), toJSONMap(after)
+	changes = map[string]lib.AuditChange{}
+	for key, value := range beforeMap {
+		if key == "updatedAt" {
+			continue
+		}
+		if !reflect.DeepEqual(value, afterMap[key]) {
+			changes[key] = lib.AuditChange{Before: value, After: afterMap[key]}
+		}
+	}
+	for key, value := range afterMap {
+		if _, ok := beforeMap[key]; !ok && key != "updatedAt" {
+			changes[key] = lib.AuditChange{After: value}
+		}
+	}
+	return
+}
+
+func toJSONMap(value interface{}) (result map[string]interface{}) {
+	result = map[string]interface{}{}
+	if value == nil {
+		return
+	}
+	b, err := json.Marshal(value)
+	if err != nil {
+		return
+	}
+	_ = json.Unmarshal(b, &result)
+	return
+}
+
+// GetAuditEntries retrieves audit entries of all reports. Callers have to make sure the requesting user is an admin.
+//
+// Parameters:
+// - args: A map of query arguments, including actor, action, reportId, since, until, limit and offset.
+//
+// Returns:
+// - entries: The audit entries, latest first.
+// - err: An error if the operation fails.
+func (r *Client) GetAuditEntries(args map[string][]string) (entries []lib.AuditEntry, err error) {
+	return findAuditEntries(auditFilter(args), args)
+}
+
+// GetReportAuditEntries retrieves the audit entries of a report. Requires the administrate permission.
+func (r *Client) GetReportAuditEntries(reportId string, args map[string][]string, authTokenString string) (entries []lib.AuditEntry, err error) {
+	claims, err := jwt.Parse(authTokenString)
+	if err != nil {
+		return
+	}
+	_, err = r.getReportModel(reportId, claims, PermissionAdministrate)
+	if err != nil {
+		return
+	}
+	filter := auditFilter(args)
+	filter["reportid"] = reportId
+	return findAuditEntries(filter, args)
+}
+
+func auditFilter(args map[string][]string) bson.M {
+	filter := bson.M{}
+	if val, ok := args["actor"]; ok {
+		filter["actor"] = val[0]
+	}
+	if val, ok := args["action"]; ok {
+		filter["action"] = bson.M{"$in": val}
+	}
+	if val, ok := args["reportId"]; ok {
+		filter["reportid"] = val[0]
+	}
+	timestamp := bson.M{}
+	if val, ok := args["since"]; ok {
+		if t, err := time.Parse(time.RFC3339, val[0]); err == nil {
+			timestamp["$gte"] = t
+		}
+	}
+	if val, ok := args["until"]; ok {
+		if t, err := time.Parse(time.RFC3339, val[0]); err == nil {
+			timestamp["$lt"] = t
+		}
+	}
+	if len(timestamp) > 0 {
+		filter["timestamp"] = timestamp
+	}
+	return filter
+}
+
+func findAuditEntries(filter bson.M, args map[string][]string) (entries []lib.AuditEntry, err error) {
+	opt := options.Find().SetSort(bson.M{"timestamp": -1})
+	if val, ok := args["limit"]; ok {
+		limit, _ := strconv.ParseInt(val[0], 10, 64)
+		opt.SetLimit(limit)
+	}
+	if val, ok := args["offset"]; ok {
+		skip, _ := strconv.ParseInt(val[0], 10, 64)
+		opt.SetSkip(skip)
+	}
+	cur, err := Audit().Find(CTX, filter, opt)
+	if err != nil {
+		return
+	}
+	entries = []lib.AuditEntry{}
+	err = cur.All(CTX, &entries)
+	for _, entry := range entries {
+		for key, change := range entry.Changes {
+			entry.Changes[key] = lib.AuditChange{Before: plainDocuments(change.Before), After: plainDocuments(change.After)}
+		}
+	}
+	return
+}
+
+// auditWithoutSecrets removes webhook secrets and S3 secret keys from the changes of webhooks and targets.
+func auditWithoutSecrets(changes map[string]lib.AuditChange) {
+	for _, key := range []string{"webhooks", "targets"} {
+		change, ok := changes[key]
+		if !ok {
+			continue
+		}
+		change.Before, change.After = listWithoutSecrets(change.Before), listWithoutSecrets(change.After)
+		changes[key] = change
+	}
+}
+
+func listWithoutSecrets(value interface{}) interface{} {
+	b, err := json.Marshal(value)
+	if err != nil {
+		return nil
+	}
+	var items []map[string]interface{}
+	if err = json.Unmarshal(b, &items); err != nil || items == nil {
+		return nil
+	}
+	for _, item := range items {
+		delete(item, "secret")
+		if s3, ok := item["s3"].(map[string]interface{}); ok {
+			delete(s3, "secretKey")
+		}
+	}
+	return items
+}
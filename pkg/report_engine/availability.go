@@ -0,0 +1,127 @@
+/*
+ * Copyright 2025 InfAI (CC SES)
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package report_engine
+
+import (
+	"time"
+
+	connectionLogModels "github.com/SENERGY-Platform/connection-log/pkg/model"
+	jsreportModels "github.com/SENERGY-Platform/reporting-service/pkg/apis/jsreport/models"
+)
+
+// calculateAvailability computes the connection statistics of a device within the window from start to end.
+// If the device has no previous state, the time before its first known state is not taken into account.
+func calculateAvailability(states connectionLogModels.HistoricalStates, start time.Time, end time.Time) (availability jsreportModels.Availability) {
+	var (
+		known        bool
+		connected    bool
+		since        time.Time
+		outageStart  time.Time
+		uptime       time.Duration
+		observed     time.Duration
+		longest      time.Duration
+		disconnected int
+	)
+	if states.PrevState != nil {
+		known = true
+		connected = states.PrevState.Connected
+		since = start
+		outageStart = start
+	}
+	for _, state := range states.States {
+		t := state.Time
+		if t.Before(start) {
+			t = start
+		}
+		if t.After(end) {
+			t = end
+		}
+		if known {
+			observed += t.Sub(since)
+			if connected {
+				uptime += t.Sub(since)
+			}
+			if connected && !state.Connected {
+				disconnected++
+				outageStart = t
+			}
+			if !connected && state.Connected {
+				longest = max(longest, t.Sub(outageStart))
+			}
+		} else if !state.Connected {
+			outageStart = t
+		}
+		known = true
+		connected = state.Connected
+		since = t
+	}
+	if !known {
+		return
+	}
+	observed += end.Sub(since)
+	if connected {
+		uptime += end.Sub(since)
+	} else {
+		availability.OfflineSeconds = int64(end.Sub(outageStart).Seconds())
+		longest = max(longest, end.Sub(outageStart))
+	}
+	availability.Online = connected
+	availability.Disconnects = disconnected
+	availability.LongestOutageSeconds = int64(longest.Seconds())
+	availability.ObservedSeconds = int64(observed.Seconds())
+	availability.UptimeSeconds = int64(uptime.Seconds())
+	if observed > 0 {
+		percent := float64(uptime) / float64(observed) * 100
+		availability.AvailabilityPercent = &percent
+	}
+	if disconnected > 0 {
+		mtbf := availability.UptimeSeconds / int64(disconnected)
+		availability.MTBFSeconds = &mtbf
+	}
+	return
+}
+
+// summarizeAvailability aggregates the availability statistics of multiple devices.
+func summarizeAvailability(deviceStates []jsreportModels.DeviceState) (summary jsreportModels.AvailabilitySummary) {
+	var uptime, observed int64
+	summary.Devices = len(deviceStates)
+	for _, deviceState := range deviceStates {
+		availability := deviceState.Availability
+		if availability.AvailabilityPercent == nil {
+			continue
+		}
+		if availability.Online {
+			summary.OnlineDevices++
+		} else {
+			summary.OfflineDevices++
+		}
+		uptime += availability.UptimeSeconds
+		observed += availability.ObservedSeconds
+		summary.Disconnects += availability.Disconnects
+		summary.LongestOutageSeconds = max(summary.LongestOutageSeconds, availability.LongestOutageSeconds)
+		summary.OfflineSeconds = max(summary.OfflineSeconds, availability.OfflineSeconds)
+	}
+	if observed > 0 {
+		percent := float64(uptime) / float64(observed) * 100
+		summary.AvailabilityPercent = &percent
+	}
+	if summary.Disconnects > 0 {
+		mtbf := uptime / int64(summary.Disconnects)
+		summary.MTBFSeconds = &mtbf
+	}
+	return
+}
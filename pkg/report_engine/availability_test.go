@@ -0,0 +1,131 @@
+/*
+ * Copyright 2025 InfAI (CC SES)
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package report_engine
+
+import (
+	"math"
+	"testing"
+	"time"
+
+	connectionLogModels "github.com/SENERGY-Platform/connection-log/pkg/model"
+	jsreportModels "github.com/SENERGY-Platform/reporting-service/pkg/apis/jsreport/models"
+)
+
+func TestCalculateAvailability(t *testing.T) {
+	start := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	end := start.Add(10 * time.Hour)
+	at := func(hours float64, connected bool) connectionLogModels.State {
+		return connectionLogModels.State{Time: start.Add(time.Duration(hours * float64(time.Hour))), Connected: connected}
+	}
+	hour := int64(3600)
+	for name, test := range map[string]struct {
+		states  connectionLogModels.HistoricalStates
+		percent *float64
+		want    jsreportModels.Availability
+	}{
+		"no state": {},
+		"always online": {
+			states:  connectionLogModels.HistoricalStates{PrevState: &connectionLogModels.State{Connected: true}},
+			percent: ptr(100.0),
+			want:    jsreportModels.Availability{Online: true, ObservedSeconds: 10 * hour, UptimeSeconds: 10 * hour},
+		},
+		"always offline": {
+			states:  connectionLogModels.HistoricalStates{PrevState: &connectionLogModels.State{Connected: false}},
+			percent: ptr(0.0),
+			want:    jsreportModels.Availability{ObservedSeconds: 10 * hour, OfflineSeconds: 10 * hour, LongestOutageSeconds: 10 * hour},
+		},
+		"outages": {
+			states: connectionLogModels.HistoricalStates{
+				PrevState: &connectionLogModels.State{Connected: true},
+				States:    []connectionLogModels.State{at(2, false), at(3, true), at(8, false)},
+			},
+			percent: ptr(70.0),
+			want: jsreportModels.Availability{
+				Disconnects:          2,
+				LongestOutageSeconds: 2 * hour,
+				MTBFSeconds:          ptr(7 * hour / 2),
+				OfflineSeconds:       2 * hour,
+				ObservedSeconds:      10 * hour,
+				UptimeSeconds:        7 * hour,
+			},
+		},
+		"registered within window": {
+			states:  connectionLogModels.HistoricalStates{States: []connectionLogModels.State{at(4, true)}},
+			percent: ptr(100.0),
+			want:    jsreportModels.Availability{Online: true, ObservedSeconds: 6 * hour, UptimeSeconds: 6 * hour},
+		},
+		"states outside window": {
+			states:  connectionLogModels.HistoricalStates{States: []connectionLogModels.State{at(-1, true), at(12, false)}},
+			percent: ptr(100.0),
+			want:    jsreportModels.Availability{Disconnects: 1, MTBFSeconds: ptr(10 * hour), ObservedSeconds: 10 * hour, UptimeSeconds: 10 * hour},
+		},
+	} {
+		got := calculateAvailability(test.states, start, end)
+		checkPercent(t, name, got.AvailabilityPercent, test.percent)
+		checkMTBF(t, name, got.MTBFSeconds, test.want.MTBFSeconds)
+		got.AvailabilityPercent, got.MTBFSeconds, test.want.MTBFSeconds = nil, nil, nil
+		if got != test.want {
+			t.Errorf("%s: availability = %+v, want %+v", name, got, test.want)
+		}
+	}
+}
+
+func TestSummarizeAvailability(t *testing.T) {
+	hour := int64(3600)
+	summary := summarizeAvailability([]jsreportModels.DeviceState{
+		{Availability: jsreportModels.Availability{AvailabilityPercent: ptr(70.0), Disconnects: 2, LongestOutageSeconds: 2 * hour, OfflineSeconds: 2 * hour, ObservedSeconds: 10 * hour, UptimeSeconds: 7 * hour}},
+		{Availability: jsreportModels.Availability{AvailabilityPercent: ptr(100.0), Online: true, ObservedSeconds: 10 * hour, UptimeSeconds: 10 * hour}},
+		{Availability: jsreportModels.Availability{}}, // no state known, only counted
+	})
+	checkPercent(t, "summary", summary.AvailabilityPercent, ptr(85.0))
+	checkMTBF(t, "summary", summary.MTBFSeconds, ptr(17*hour/2))
+	summary.AvailabilityPercent, summary.MTBFSeconds = nil, nil
+	want := jsreportModels.AvailabilitySummary{Devices: 3, OnlineDevices: 1, OfflineDevices: 1, Disconnects: 2, LongestOutageSeconds: 2 * hour, OfflineSeconds: 2 * hour}
+	if summary != want {
+		t.Errorf("summary = %+v, want %+v", summary, want)
+	}
+
+	empty := summarizeAvailability(nil)
+	if empty.Devices != 0 || empty.AvailabilityPercent != nil || empty.MTBFSeconds != nil {
+		t.Errorf("empty summary = %+v", empty)
+	}
+}
+
+func ptr[T any](value T) *T {
+	return &value
+}
+
+func checkPercent(t *testing.T, name string, got *float64, want *float64) {
+	t.Helper()
+	if (got == nil) != (want == nil) || (got != nil && math.Abs(*got-*want) > 1e-9) {
+		t.Errorf("%s: availability percent = %v, want %v", name, deref(got), deref(want))
+	}
+}
+
+func checkMTBF(t *testing.T, name string, got *int64, want *int64) {
+	t.Helper()
+	if (got == nil) != (want == nil) || (got != nil && *got != *want) {
+		t.Errorf("%s: mtbf = %v, want %v", name, deref(got), deref(want))
+	}
+}
+
+func deref[T any](value *T) interface{} {
+	if value == nil {
+		return nil
+	}
+	return *value
+}
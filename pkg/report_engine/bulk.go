@@ -0,0 +1,93 @@
+/*
+ * Copyright 2025 InfAI (CC SES)
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package report_engine
+
+import (
+	"fmt"
+
+	"github.com/SENERGY-Platform/reporting-service/lib"
+	"github.com/SENERGY-Platform/reporting-service/pkg/util"
+	"github.com/SENERGY-Platform/service-commons/pkg/jwt"
+)
+
+// BulkReports executes a list of operations on reports. A failing operation does not stop the remaining ones.
+// Every operation requires the same permission as the respective single report method.
+//
+// Parameters:
+// - request: The operations to execute.
+// - authTokenString: The authentication token string.
+//
+// Returns:
+// - results: The outcome per operation and report, in request order.
+// - err: An error if the token is invalid.
+func (r *Client) BulkReports(request lib.BulkRequest, authTokenString string) (results []lib.BulkResult, err error) {
+	claims, err := jwt.Parse(authTokenString)
+	if err != nil {
+		return
+	}
+	results = []lib.BulkResult{}
+	for _, operation := range request.Operations {
+		for _, id := range operation.ReportIds {
+			result := lib.BulkResult{Action: operation.Action, ReportId: id}
+			result.FileId, result.Err = r.bulkOperation(operation, id, claims, authTokenString)
+			result.Success = result.Err == nil
+			results = append(results, result)
+		}
+	}
+	return
+}
+
+func (r *Client) bulkOperation(operation lib.BulkOperation, id string, claims jwt.Token, authTokenString string) (fileId string, err error) {
+	switch operation.Action {
+	case lib.BulkActionDelete:
+		return "", r.DeleteReport(id, authTokenString, false)
+	case lib.BulkActionRun:
+		var report lib.Report
+		report, err = r.getReportModel(id, claims, PermissionExecute)
+		if err != nil {
+			return
+		}
+		report, fileId, err = r.CreateReportFile(report, authTokenString)
+		if err != nil {
+			return
+		}
+		// like a single run, failed deliveries are recorded in the runs of the report and do not fail the operation
+		if _, deliveryErr := r.DeliverReportFile(report, fileId, authTokenString, false); deliveryErr != nil {
+			util.Logger.Error("could not deliver report file "+fileId, "error", deliveryErr)
+		}
+		return
+	case lib.BulkActionPause, lib.BulkActionResume, lib.BulkActionCron, lib.BulkActionEmailReceivers:
+		var report lib.Report
+		report, err = r.getReportModel(id, claims, PermissionAdministrate)
+		if err != nil {
+			return
+		}
+		switch operation.Action {
+		case lib.BulkActionPause:
+			report.Paused = true
+		case lib.BulkActionResume:
+			report.Paused = false
+		case lib.BulkActionCron:
+			report.Cron = operation.Cron
+		case lib.BulkActionEmailReceivers:
+			report.EmailReceivers = operation.EmailReceivers
+		}
+		return "", r.updateReportModel(report, claims, PermissionAdministrate)
+	default:
+		return "", fmt.Errorf("%w: unknown action %q", ErrValidation, operation.Action)
+	}
+}
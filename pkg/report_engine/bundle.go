@@ -0,0 +1,224 @@
+/*
+ * Copyright 2025 InfAI (CC SES)
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package report_engine
+
+import (
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/SENERGY-Platform/reporting-service/lib"
+	"github.com/SENERGY-Platform/service-commons/pkg/jwt"
+)
+
+// ExportReport creates a portable bundle of a report definition. Requires the read permission.
+//
+// Parameters:
+// - id: The ID of the report to export.
+// - authTokenString: The authentication token string.
+//
+// Returns:
+// - bundle: The report definition with symbolic device references.
+// - err: An error if the operation fails.
+func (r *Client) ExportReport(id string, authTokenString string) (bundle lib.ReportBundle, err error) {
+	claims, err := jwt.Parse(authTokenString)
+	if err != nil {
+		return
+	}
+	report, err := r.getReportModel(id, claims, PermissionRead)
+	if err != nil {
+		return
+	}
+	definition := portableDefinition(report)
+	definition.TemplateId = "" // template ids differ between tenants, the template is referenced by name
+
+	devices := map[string]*lib.BundleDevice{}
+	refs := map[string]string{}
+	collectQueryDevices(definition.Data, devices)
+	collectTriggerDevices(definition.Triggers, devices)
+	for _, device := range devices {
+		slices.Sort(device.ServiceIds)
+		bundle.Devices = append(bundle.Devices, *device)
+	}
+	slices.SortFunc(bundle.Devices, func(a, b lib.BundleDevice) int { return strings.Compare(a.DeviceId, b.DeviceId) })
+	for i := range bundle.Devices {
+		bundle.Devices[i].Ref = "device" + strconv.Itoa(i+1)
+		refs[bundle.Devices[i].DeviceId] = bundle.Devices[i].Ref
+	}
+	definition.Data = substituteQueryIds(definition.Data, refs, nil, map[string]bool{})
+	substituteTriggerIds(definition.Triggers, refs, map[string]bool{})
+
+	bundle.FormatVersion = lib.ReportBundleFormatVersion
+	bundle.Driver = r.Driver.Name()
+	bundle.TemplateName = report.TemplateName
+	bundle.Report = definition
+	if bundle.Devices == nil {
+		bundle.Devices = []lib.BundleDevice{}
+	}
+	return
+}
+
+// ImportReport validates a report bundle, maps its device references and creates a report owned by the requesting user.
+// Recipients, webhooks and targets are not imported, they have to be added to the created report.
+//
+// Parameters:
+// - request: The bundle and the mapping of its device references.
+// - dryRun: If true, the report is validated and returned but not saved.
+// - authTokenString: The authentication token string.
+//
+// Returns:
+// - result: The created report, or the report which would be created in dry-run mode.
+// - err: ErrValidation if the bundle or mapping is invalid, another error if the operation fails.
+func (r *Client) ImportReport(request lib.ImportRequest, dryRun bool, authTokenString string) (result lib.ImportResult, err error) {
+	claims, err := jwt.Parse(authTokenString)
+	if err != nil {
+		return
+	}
+	bundle := request.Bundle
+	if bundle.FormatVersion != lib.ReportBundleFormatVersion {
+		return result, fmt.Errorf("%w: unsupported bundle format version %d", ErrValidation, bundle.FormatVersion)
+	}
+	if bundle.Driver != r.Driver.Name() {
+		return result, fmt.Errorf("%w: bundle was exported for driver %q", ErrValidation, bundle.Driver)
+	}
+	templates, err := r.Driver.GetTemplates(authTokenString)
+	if err != nil {
+		return
+	}
+	index := slices.IndexFunc(templates, func(template lib.Template) bool { return template.Name == bundle.TemplateName })
+	if index < 0 {
+		return result, fmt.Errorf("%w: unknown template %q", ErrValidation, bundle.TemplateName)
+	}
+
+	deviceIds := map[string]string{}
+	var missing []string
+	for _, device := range bundle.Devices {
+		deviceId, ok := request.DeviceMapping[device.Ref]
+		if !ok || deviceId == "" {
+			missing = append(missing, device.Ref)
+			continue
+		}
+		deviceIds[device.Ref] = deviceId
+	}
+	if len(missing) > 0 {
+		return result, fmt.Errorf("%w: missing device mapping for %s", ErrValidation, strings.Join(missing, ", "))
+	}
+	for ref := range request.DeviceMapping {
+		if _, ok := deviceIds[ref]; !ok {
+			return result, fmt.Errorf("%w: unknown device reference %q", ErrValidation, ref)
+		}
+	}
+	used := map[string]bool{}
+	report := portableDefinition(bundle.Report)
+	report.Data = substituteQueryIds(report.Data, deviceIds, nil, used)
+	substituteTriggerIds(report.Triggers, deviceIds, used)
+	for ref := range deviceIds {
+		if !used["device:"+ref] {
+			return result, fmt.Errorf("%w: device reference %q is not used by any query or trigger", ErrValidation, ref)
+		}
+	}
+	report.UserId = claims.GetUserId()
+	report.TemplateName = templates[index].Name
+	report.TemplateId = templates[index].Id
+	// validate like the import itself, so a successful dry run is not rejected on import
+	err = r.validateReport(&report, lib.Report{})
+	if err != nil {
+		return
+	}
+	_, err = calculateNextSchedule(report)
+	if err != nil {
+		return
+	}
+
+	result.Devices = deviceIds
+	result.Changes = AuditChanges(withoutSecrets(bundle.Report), withoutSecrets(report))
+	result.DryRun = dryRun
+	if dryRun {
+		result.Report = withoutSecrets(report)
+		return
+	}
+	result.Report, err = r.SaveReportModel(report, authTokenString)
+	return
+}
+
+// portableDefinition strips the user-specific fields from the definition of a report: recipients, webhooks and targets.
+// Triggers are copied, so their device ids may be substituted.
+func portableDefinition(report lib.Report) lib.Report {
+	definition := reportDefinition(report)
+	definition.EmailReceivers = nil
+	definition.Recipients = nil
+	definition.ReplyTo = nil
+	definition.Webhooks = nil
+	definition.Targets = nil
+	if definition.Conditions != nil {
+		conditions := *definition.Conditions
+		conditions.RedirectTo = nil
+		if conditions.Otherwise == lib.ConditionActionRedirect {
+			conditions.Otherwise = lib.ConditionActionSkip
+		}
+		definition.Conditions = &conditions
+	}
+	if definition.Triggers != nil {
+		definition.Triggers = slices.Clone(definition.Triggers)
+		for i := range definition.Triggers {
+			definition.Triggers[i].DeviceIds = slices.Clone(definition.Triggers[i].DeviceIds)
+		}
+	}
+	return definition
+}
+
+// collectTriggerDevices collects the devices of device offline triggers.
+func collectTriggerDevices(triggers []lib.Trigger, devices map[string]*lib.BundleDevice) {
+	for _, trigger := range triggers {
+		for _, deviceId := range trigger.DeviceIds {
+			if _, ok := devices[deviceId]; !ok {
+				devices[deviceId] = &lib.BundleDevice{DeviceId: deviceId, ServiceIds: []string{}}
+			}
+		}
+	}
+}
+
+// substituteTriggerIds replaces the device ids of triggers in place, mapping old ids to new ids.
+func substituteTriggerIds(triggers []lib.Trigger, deviceIds map[string]string, used map[string]bool) {
+	for _, trigger := range triggers {
+		for i, deviceId := range trigger.DeviceIds {
+			if newId, ok := deviceIds[deviceId]; ok {
+				used["device:"+deviceId] = true
+				trigger.DeviceIds[i] = newId
+			}
+		}
+	}
+}
+
+// collectQueryDevices collects the devices and their services used by all queries of data.
+func collectQueryDevices(data map[string]lib.ReportObject, devices map[string]*lib.BundleDevice) {
+	for _, object := range data {
+		if object.Query != nil && object.Query.DeviceId != nil {
+			device, ok := devices[*object.Query.DeviceId]
+			if !ok {
+				device = &lib.BundleDevice{DeviceId: *object.Query.DeviceId, ServiceIds: []string{}}
+				devices[device.DeviceId] = device
+			}
+			if object.Query.ServiceId != nil && !slices.Contains(device.ServiceIds, *object.Query.ServiceId) {
+				device.ServiceIds = append(device.ServiceIds, *object.Query.ServiceId)
+			}
+		}
+		collectQueryDevices(object.Fields, devices)
+		collectQueryDevices(object.Children, devices)
+	}
+}
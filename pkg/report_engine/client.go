@@ -18,21 +18,21 @@ package report_engine
 
 import (
 	"context"
-	"encoding/base64"
 	"errors"
 	"fmt"
+	"slices"
 	"sort"
 	"strconv"
 	"strings"
 	"time"
 
-	connectionLogModels "github.com/SENERGY-Platform/connection-log/pkg/model"
 	snrgyModels "github.com/SENERGY-Platform/models/go/models"
 	"github.com/SENERGY-Platform/reporting-service/lib"
 	"github.com/SENERGY-Platform/reporting-service/pkg/apis/connection_log"
 	"github.com/SENERGY-Platform/reporting-service/pkg/apis/device_manager"
 	jsreportModels "github.com/SENERGY-Platform/reporting-service/pkg/apis/jsreport/models"
-	"github.com/SENERGY-Platform/reporting-service/pkg/apis/senergy_devices"
+	"github.com/SENERGY-Platform/reporting-service/pkg/apis/kafka"
+	"github.com/SENERGY-Platform/reporting-service/pkg/apis/mailer"
 	"github.com/SENERGY-Platform/reporting-service/pkg/config"
 	"github.com/SENERGY-Platform/reporting-service/pkg/util"
 	"github.com/prometheus/client_golang/prometheus"
@@ -40,6 +40,7 @@ import (
 
 	"github.com/SENERGY-Platform/reporting-service/pkg/apis/senergy_db_v3"
 	"github.com/SENERGY-Platform/service-commons/pkg/jwt"
+	timescaleModels "github.com/SENERGY-Platform/timescale-wrapper/pkg/model"
 	"github.com/globalsign/mgo/bson"
 	"github.com/google/uuid"
 	"github.com/robfig/cron/v3"
@@ -55,10 +56,11 @@ var dataPointsTSDBCounter = promauto.NewCounterVec(prometheus.CounterOpts{
 type Client struct {
 	Driver        ReportingDriver
 	DBClient      *senergy_db_v3.Client
-	DevicesClient *senergy_devices.Client
 	Config        *config.Config
 	DeviceManager *device_manager.Client
 	ConnectionLog *connection_log.Client
+	Mailer        mailer.Mailer
+	Publisher     *kafka.Publisher // publishes events, nil if Kafka is not configured
 }
 
 // NewClient creates a new client with the given reporting driver.
@@ -68,24 +70,41 @@ type Client struct {
 //
 // Returns:
 // - client: The newly created client.
-func NewClient(driver ReportingDriver, config *config.Config) *Client {
+// - err: An error if the mail config is invalid.
+func NewClient(driver ReportingDriver, config *config.Config) (*Client, error) {
 	dbClient := senergy_db_v3.NewClient(
 		config.SNRGY.Url,
 		config.SNRGY.Port,
 	)
-	devicesClient := senergy_devices.NewClient(
-		config.SNRGY.Url,
-		config.SNRGY.Port,
-	)
 	deviceManagerClient := device_manager.NewClient(
 		config.SNRGY.Url,
 		config.SNRGY.Port,
+		config.Devices.PageSize,
+		config.Devices.MaxDevices,
+		config.Devices.RequestTimeout,
 	)
 	connectionLogClient := connection_log.NewClient(
 		config.SNRGY.Url,
 		config.SNRGY.Port,
+		config.ConnectionLog.ChunkSize,
+		config.ConnectionLog.MaxConcurrency,
+		config.ConnectionLog.RequestTimeout,
 	)
-	return &Client{Driver: driver, DBClient: dbClient, DevicesClient: devicesClient, Config: config, DeviceManager: deviceManagerClient, ConnectionLog: connectionLogClient}
+	mailClient, err := mailer.New(config.Mail)
+	if err != nil {
+		return nil, fmt.Errorf("invalid mail config: %w", err)
+	}
+	if err = validateKafkaConfig(config); err != nil {
+		return nil, fmt.Errorf("invalid kafka config: %w", err)
+	}
+	if err = validateTriggerConfig(config); err != nil {
+		return nil, fmt.Errorf("invalid trigger config: %w", err)
+	}
+	var publisher *kafka.Publisher
+	if config.Kafka.Bootstrap != "" {
+		publisher = kafka.NewPublisher(config.Kafka.Bootstrap, config.Kafka.Timeout)
+	}
+	return &Client{Driver: driver, DBClient: dbClient, Config: config, DeviceManager: deviceManagerClient, ConnectionLog: connectionLogClient, Mailer: mailClient, Publisher: publisher}, nil
 }
 
 // GetTemplates retrieves a list of available report templates.
@@ -124,15 +143,47 @@ func (r *Client) GetTemplatePreviewById(id string, authString string) (content [
 // Returns:
 // - err: An error if the operation fails.
 func (r *Client) CreateReportFile(reportRequest lib.Report, authTokenString string) (resultReport lib.Report, reportFileId string, err error) {
-	reportModel, err := r.GetReportModel(reportRequest.Id, authTokenString)
+	claims, err := jwt.Parse(authTokenString)
+	if err != nil {
+		return
+	}
+	reportModel, err := r.getReportModel(reportRequest.Id, claims, PermissionExecute)
+	if err != nil && !errors.Is(err, mongo.ErrNoDocuments) {
+		return
+	}
 	// if no report model is found, create a new one
-	if errors.Is(err, mongo.ErrNoDocuments) || reportModel.Id == "" {
-		reportModel, _ = r.SaveReportModel(reportRequest, authTokenString)
+	if reportModel.Id == "" {
+		reportModel, err = r.saveReportModel(reportRequest, claims)
+		if err != nil {
+			return
+		}
+		reportRequest = reportModel
+	} else if !hasPermission(reportModel, claims, PermissionAdministrate) {
+		// users without the administrate permission may only run the stored report
 		reportRequest = reportModel
-	} else if err != nil {
-		return
 	}
-	reportRequest.ReportFiles = reportModel.ReportFiles
+	// save changes of the definition before running it
+	if _, changed := nextVersion(reportModel, reportRequest); changed {
+		err = r.updateReportModel(reportRequest, claims, PermissionAdministrate)
+		if err != nil {
+			return
+		}
+		reportModel, err = r.getReportModel(reportRequest.Id, claims, PermissionExecute)
+		if err != nil {
+			return
+		}
+	}
+	reportRequest = reportModel
+	defer func() {
+		if runErr := recordRun(reportModel.Id, err); runErr != nil {
+			util.Logger.Error("could not record run status of report "+reportModel.Id, "error", runErr)
+		}
+		if err != nil {
+			event := reportEvent(EventRunFailed, reportModel)
+			event.Error = err.Error()
+			r.publishEvent(event)
+		}
+	}()
 
 	// set report file data
 	reportData, err := r.setReportFileData(reportRequest.Data, authTokenString, reportRequest.Id)
@@ -140,24 +191,68 @@ func (r *Client) CreateReportFile(reportRequest lib.Report, authTokenString stri
 		return
 	}
 
+	// the query windows have been resolved by setReportFileData
+	periodStart, periodEnd := queryPeriod(reportRequest.Data, time.Now())
+	condition, err := evaluateConditions(reportRequest.Conditions, reportData)
+	if err != nil {
+		return
+	}
+
 	// create the actual report file using the underlying driver
 	reportFileId, reportFileType, reportFileLink, err := r.Driver.CreateReport(reportRequest.Name, reportRequest.TemplateName, reportData, authTokenString)
 	if err != nil {
 		return
 	}
 
-	// add the report file model to the report model
-	reportRequest.ReportFiles = append(reportRequest.ReportFiles, lib.ReportFile{Id: reportFileId, Type: reportFileType, Link: reportFileLink, CreatedAt: time.Now()})
-	reportRequest.CreatedAt = reportModel.CreatedAt
-	err = r.UpdateReportModel(reportRequest, authTokenString)
+	// add the report file models to the report model, concurrent changes of the definition are kept
+	reportFile := lib.ReportFile{
+		Id:            reportFileId,
+		Type:          reportFileType,
+		Link:          reportFileLink,
+		CreatedAt:     time.Now(),
+		ReportVersion: max(reportModel.Version, 1),
+		PeriodStart:   periodStart,
+		PeriodEnd:     periodEnd,
+		Values:        templateValues(reportData),
+		Condition:     condition,
+		RunId:         uuid.New().String(),
+	}
+	// additional outputs render the same data
+	outputFiles, err := r.createOutputFiles(reportRequest, reportData, reportFile, authTokenString)
+	if err != nil {
+		r.deleteDriverFiles([]lib.ReportFile{reportFile}, authTokenString)
+		return
+	}
+	files := append([]lib.ReportFile{reportFile}, outputFiles...)
+	ts, err := calculateNextSchedule(reportRequest)
+	if err != nil {
+		return
+	}
+	err = appendReportFiles(reportRequest.Id, files, ts)
 	if err != nil {
 		return
 	}
+	reportRequest.ReportFiles = append(reportRequest.ReportFiles, files...)
+	for _, file := range files {
+		r.publishEvent(fileEvent(EventFileCreated, reportRequest, file))
+	}
+	reportRequest.ScheduledFor = ts
 
 	resultReport = reportRequest
 	return
 }
 
+// recordRun saves the outcome of the latest run of a report.
+func recordRun(reportId string, runErr error) (err error) {
+	status := lib.RunStatus{Status: lib.RunStatusSuccess, Time: time.Now()}
+	if runErr != nil {
+		status.Status = lib.RunStatusFailed
+		status.Error = runErr.Error()
+	}
+	_, err = Reports().UpdateOne(CTX, bson.M{"_id": reportId}, bson.M{"$set": bson.M{"lastrun": status}})
+	return
+}
+
 // setReportFileData recursively sets report data based on the input data and authorization token.
 //
 // Parameters:
@@ -184,13 +279,18 @@ func (r *Client) setReportFileData(data map[string]lib.ReportObject, authToken s
 				if err != nil {
 					return
 				}
-				responseData, err = r.DBClient.Query(authToken, *value.Query, *value.QueryOptions)
+				responseData, err = r.queryValues(authToken, *value.Query, *value.QueryOptions, userId, reportId)
 				if err != nil {
 					return
 				}
-				dataPointsTSDBCounter.WithLabelValues(userId, reportId).Add(float64(len(responseData)))
-				responseData = r.filterQueryValues(responseData)
-				if len(responseData) > 0 {
+				if value.QueryOptions.Compare != nil {
+					var previousData []interface{}
+					previousData, err = r.queryPreviousValues(authToken, *value.Query, *value.QueryOptions, userId, reportId)
+					if err != nil {
+						return
+					}
+					resultData[key] = compareValues(firstValue(responseData), firstValue(previousData))
+				} else if len(responseData) > 0 {
 					resultData[key] = responseData[0]
 				}
 			} else {
@@ -239,86 +339,109 @@ func (r *Client) setReportFileData(data map[string]lib.ReportObject, authToken s
 				if err != nil {
 					return nil, err
 				}
-				responseData, err = r.DBClient.Query(authToken, *value.Query, *value.QueryOptions)
+				responseData, err = r.queryValues(authToken, *value.Query, *value.QueryOptions, userId, reportId)
 				if err != nil {
 					return
 				}
-				dataPointsTSDBCounter.WithLabelValues(userId, reportId).Add(float64(len(responseData)))
-				responseData = r.filterQueryValues(responseData)
-				resultData[key] = responseData
+				if value.QueryOptions.Compare != nil {
+					var previousData []interface{}
+					previousData, err = r.queryPreviousValues(authToken, *value.Query, *value.QueryOptions, userId, reportId)
+					if err != nil {
+						return
+					}
+					resultData[key] = compareValues(responseData, previousData)
+				} else {
+					resultData[key] = responseData
+				}
 			} else if value.DeviceQuery != nil {
-				var responseDataDevices []snrgyModels.Device
-				var responseDataStates []connectionLogModels.ResourceHistoricalStates
-
-				// get the duration from the last field
-				var duration time.Duration
-				duration, err = ParseDuration(*value.DeviceQuery.Last)
+				var requestData []jsreportModels.DeviceState
+				requestData, err = r.getDeviceStates(authToken, *value.DeviceQuery)
 				if err != nil {
 					return
 				}
-
-				// get device data
-				responseDataDevices, err = r.DeviceManager.Query(authToken)
-
-				if err != nil {
-					return
+				if value.DeviceQuery.Summary {
+					resultData[key] = jsreportModels.DeviceStatesSummary{Devices: requestData, Availability: summarizeAvailability(requestData)}
+				} else {
+					resultData[key] = requestData
 				}
+			}
+		}
+	}
+	return
+}
 
-				// make ids list
-				deviceIds := make([]string, 0)
-				for _, device := range responseDataDevices {
-					deviceIds = append(deviceIds, device.Id)
-				}
+// getDeviceStates collects the connection history of all devices of the user.
+// Devices are streamed page by page from the device manager and the states of each page are requested right away.
+func (r *Client) getDeviceStates(authToken string, deviceQuery lib.DeviceQuery) (requestData []jsreportModels.DeviceState, err error) {
+	start, end, err := getDeviceQueryWindow(deviceQuery, time.Now())
+	if err != nil {
+		return
+	}
+	ctx := context.Background()
+	err = r.DeviceManager.Query(ctx, authToken, func(devices []snrgyModels.Device) error {
+		// make ids list
+		deviceIds := make([]string, 0, len(devices))
+		for _, device := range devices {
+			deviceIds = append(deviceIds, device.Id)
+		}
 
-				// get device states data
-				responseDataStates, err = r.ConnectionLog.Query(authToken, deviceIds, duration)
-				if err != nil {
-					return
-				}
-				// make request data by putting the device and states data together,
-				// keep the old format, so the template does not need to be changed
-				var requestData []jsreportModels.DeviceState
-				for _, device := range responseDataDevices {
-					for _, deviceStates := range responseDataStates {
-						if deviceStates.ID == device.Id {
-							var logHistory jsreportModels.LogHistory
-							// start with previous state, so the graph is not empty, buit only use it, if it is not nil (device was registered during the last reporting days cycle)
-							if deviceStates.PrevState != nil {
-								logHistory.Values = append(logHistory.Values, [][3]interface{}{
-									// cut the timeline at the desired duration (from the request)
-									{time.Now().Add(-duration).Unix(), deviceStates.PrevState.Connected, time.Now().Add(-duration)},
-								}...)
-							}
+		// get device states data
+		responseDataStates, err := r.ConnectionLog.Query(ctx, authToken, deviceIds, start, end)
+		if err != nil {
+			return err
+		}
+		// make request data by putting the device and states data together,
+		// keep the old format, so the template does not need to be changed
+		for _, device := range devices {
+			for _, deviceStates := range responseDataStates {
+				if deviceStates.ID == device.Id {
+					var logHistory jsreportModels.LogHistory
+					// start with previous state, so the graph is not empty, buit only use it, if it is not nil (device was registered during the last reporting days cycle)
+					if deviceStates.PrevState != nil {
+						logHistory.Values = append(logHistory.Values, [][3]interface{}{
+							// cut the timeline at the desired duration (from the request)
+							{start.Unix(), deviceStates.PrevState.Connected, start},
+						}...)
+					}
 
-							for _, deviceState := range deviceStates.States {
-								logHistory.Values = append(logHistory.Values, [][3]interface{}{
-									{deviceState.Time.Unix(), deviceState.Connected, deviceState.Time},
-								}...)
-							}
-							// set correct device name
-							if device.Attributes != nil && hasAttributeWithKey(device.Attributes, "shared/nickname") {
-								for _, attr := range device.Attributes {
-									if attr.Key == "shared/nickname" {
-										device.Name = attr.Value
-										break
-									}
-								}
+					for _, deviceState := range deviceStates.States {
+						logHistory.Values = append(logHistory.Values, [][3]interface{}{
+							{deviceState.Time.Unix(), deviceState.Connected, deviceState.Time},
+						}...)
+					}
+					// set correct device name
+					if device.Attributes != nil && hasAttributeWithKey(device.Attributes, "shared/nickname") {
+						for _, attr := range device.Attributes {
+							if attr.Key == "shared/nickname" {
+								device.Name = attr.Value
+								break
 							}
-							requestData = append(requestData, jsreportModels.DeviceState{
-								Device:      device,
-								DisplayName: device.Name,
-								LogHistory:  logHistory,
-							})
 						}
 					}
+					requestData = append(requestData, jsreportModels.DeviceState{
+						Device:       device,
+						DisplayName:  device.Name,
+						LogHistory:   logHistory,
+						Availability: calculateAvailability(deviceStates.HistoricalStates, start, end),
+					})
 				}
-				resultData[key] = requestData
 			}
 		}
-	}
+		return nil
+	})
 	return
 }
 
+// queryValues queries the TSDB and replaces missing values.
+func (r *Client) queryValues(authToken string, query timescaleModels.QueriesRequestElement, queryOptions lib.QueryOptions, userId string, reportId string) (responseData []interface{}, err error) {
+	responseData, err = r.DBClient.Query(authToken, query, queryOptions)
+	if err != nil {
+		return
+	}
+	dataPointsTSDBCounter.WithLabelValues(userId, reportId).Add(float64(len(responseData)))
+	return r.filterQueryValues(responseData), nil
+}
+
 func (r *Client) filterQueryValues(queryValues []interface{}) (filteredData []interface{}) {
 	for _, value := range queryValues {
 		if value != nil {
@@ -381,10 +504,13 @@ func (r *Client) updateStartAndEndDate(object *lib.ReportObject) (err error) {
 // - fileTypeExtension: The file type extension of the report.
 // - err: An error if the operation fails.
 func (r *Client) DownloadReportFile(reportId string, fileId string, authTokenString string) (content []byte, contentType string, fileTypeExtension string, err error) {
-	_, err = r.GetReportModel(reportId, authTokenString)
+	report, err := r.GetReportModel(reportId, authTokenString)
 	if err != nil {
 		return
 	}
+	if !slices.ContainsFunc(report.ReportFiles, func(file lib.ReportFile) bool { return file.Id == fileId }) {
+		return nil, "", "", mongo.ErrNoDocuments
+	}
 	content, contentType, fileTypeExtension, err = r.Driver.GetReportContent(fileId, authTokenString)
 	if err != nil {
 		return
@@ -402,7 +528,11 @@ func (r *Client) DownloadReportFile(reportId string, fileId string, authTokenStr
 // Returns:
 // - err: An error if the operation fails.
 func (r *Client) DeleteCreatedReportFile(reportId string, fileId string, authTokenString string) (err error) {
-	report, err := r.GetReportModel(reportId, authTokenString)
+	claims, err := jwt.Parse(authTokenString)
+	if err != nil {
+		return
+	}
+	report, err := r.getReportModel(reportId, claims, PermissionAdministrate)
 	if err != nil {
 		fmt.Println(err.Error())
 		return
@@ -412,12 +542,7 @@ func (r *Client) DeleteCreatedReportFile(reportId string, fileId string, authTok
 		fmt.Println(err.Error())
 		return
 	}
-	for index, element := range report.ReportFiles {
-		if element.Id == fileId {
-			report.ReportFiles = append(report.ReportFiles[:index], report.ReportFiles[index+1:]...)
-		}
-	}
-	err = r.UpdateReportModel(report, authTokenString)
+	err = removeReportFile(report.Id, fileId)
 	if err != nil {
 		fmt.Println(err.Error())
 		return
@@ -439,15 +564,40 @@ func (r *Client) SaveReportModel(report lib.Report, authTokenString string) (sav
 	if err != nil {
 		return
 	}
+	savedReport, err = r.saveReportModel(report, claims)
+	return withoutSecrets(savedReport), err
+}
+
+// saveReportModel creates a report owned by the user and returns it including its secrets.
+func (r *Client) saveReportModel(report lib.Report, claims jwt.Token) (savedReport lib.Report, err error) {
 	report.Id = uuid.New().String()
 	report.UserId = claims.GetUserId()
+	err = validatePermissions(report.Permissions)
+	if err != nil {
+		return
+	}
+	err = r.validateReport(&report, lib.Report{})
+	if err != nil {
+		return
+	}
 	ts, err := calculateNextSchedule(report)
 	if err != nil {
 		return
 	}
 	report.ScheduledFor = ts
 	report.CreatedAt = time.Now()
+	report.Version = 1
+	report.Revision = 1
+	err = saveReportVersion(report, report.UserId)
+	if err != nil {
+		return
+	}
 	_, err = Reports().InsertOne(CTX, report)
+	if err != nil {
+		deleteReportVersion(report.Id, report.Version)
+		return
+	}
+	r.publishEvent(reportEvent(EventReportUpdated, report))
 	savedReport = report
 	return
 }
@@ -465,23 +615,77 @@ func (r *Client) UpdateReportModel(report lib.Report, authTokenString string) (e
 	if err != nil {
 		return
 	}
-	report.UserId = claims.GetUserId()
+	return r.updateReportModel(report, claims, PermissionAdministrate)
+}
+
+// updateReportModel updates a report, if the user has the given permission, or creates it, if it does not exist.
+// Owner, permissions and files of an existing report are kept. A new version is saved, if the definition has changed.
+// Returns ErrConflict, if the revision of the report is not the stored one.
+func (r *Client) updateReportModel(report lib.Report, claims jwt.Token, permission Permission) (err error) {
+	oldReport, err := r.getReportModel(report.Id, claims, permission)
+	changed := true
+	if errors.Is(err, mongo.ErrNoDocuments) {
+		report.UserId = claims.GetUserId()
+		report.Version = 1
+		report.Revision = 1
+		err = validatePermissions(report.Permissions)
+		if err == nil {
+			err = r.validateReport(&report, lib.Report{})
+		}
+	} else if err == nil && report.Revision != oldReport.Revision {
+		err = ErrConflict
+	} else if err == nil {
+		report.UserId = oldReport.UserId
+		err = r.validateReport(&report, oldReport)
+		report.Version, changed = nextVersion(oldReport, report)
+		report.Revision = oldReport.Revision + 1
+	}
+	if err != nil {
+		return
+	}
 	ts, err := calculateNextSchedule(report)
 	if err != nil {
 		return
 	}
 	report.ScheduledFor = ts
 	report.UpdatedAt = time.Now()
-	if report.ReportFiles == nil {
-		oldReport, e := r.GetReportModel(report.Id, authTokenString)
-		if e != nil {
+	if oldReport.Id != "" && oldReport.Version == 0 {
+		// reports saved before versioning get their previous definition as first version,
+		// a conflict means a concurrent update has saved it already
+		oldReport.Version = 1
+		err = saveReportVersion(oldReport, oldReport.UserId)
+		if err != nil && !errors.Is(err, ErrConflict) {
 			return
 		}
-		report.ReportFiles = oldReport.ReportFiles
-		report.CreatedAt = oldReport.CreatedAt
 	}
-	_, err = Reports().ReplaceOne(CTX, bson.M{"_id": report.Id, "userid": claims.GetUserId()}, report, options.Replace().SetUpsert(true))
-	return
+	// the version is saved first, so concurrent updates can not store the same version number
+	if changed {
+		err = saveReportVersion(report, claims.GetUserId())
+		if err != nil {
+			return
+		}
+	}
+	if oldReport.Id == "" {
+		_, err = Reports().ReplaceOne(CTX, bson.M{"_id": report.Id, "userid": report.UserId}, report, options.Replace().SetUpsert(true))
+	} else {
+		var update bson.M
+		update, err = definitionUpdate(report)
+		if err == nil {
+			var res *mongo.UpdateResult
+			res, err = Reports().UpdateOne(CTX, revisionFilter(report.Id, oldReport.Revision), update)
+			if err == nil && res.MatchedCount == 0 {
+				err = ErrConflict
+			}
+		}
+	}
+	if err != nil {
+		if changed {
+			deleteReportVersion(report.Id, report.Version)
+		}
+		return
+	}
+	r.publishEvent(reportEvent(EventReportUpdated, report))
+	return nil
 }
 
 // DeleteReport deletes a report model and created files by its ID.
@@ -498,11 +702,13 @@ func (r *Client) DeleteReport(id string, authTokenString string, admin bool) (er
 	if err != nil {
 		return
 	}
-	req := bson.M{"_id": id, "userid": claims.GetUserId()}
+	req := bson.M{"_id": id}
+	var report lib.Report
 	if admin {
-		req = bson.M{"_id": id}
+		err = Reports().FindOne(CTX, req).Decode(&report)
+	} else {
+		report, err = r.getReportModel(id, claims, PermissionAdministrate)
 	}
-	report, err := r.GetReportModel(id, authTokenString)
 	if err != nil {
 		return
 	}
@@ -513,7 +719,20 @@ func (r *Client) DeleteReport(id string, authTokenString string, admin bool) (er
 		}
 	}
 	res := Reports().FindOneAndDelete(CTX, req)
-	return res.Err()
+	if res.Err() != nil {
+		return res.Err()
+	}
+	r.publishEvent(reportEvent(EventReportDeleted, report))
+	_, err = FileShares().DeleteMany(CTX, bson.M{"reportid": id})
+	if err != nil {
+		return
+	}
+	_, err = ReportVersions().DeleteMany(CTX, bson.M{"reportid": id})
+	if err != nil {
+		return
+	}
+	_, err = ReportRuns().DeleteMany(CTX, bson.M{"reportid": id})
+	return
 }
 
 // GetReportModel GetReport retrieves a report from the MongoDB database based on the provided ID and authentication token.
@@ -530,67 +749,82 @@ func (r *Client) GetReportModel(id string, authTokenString string) (report lib.R
 	if err != nil {
 		return
 	}
-	err = Reports().FindOne(CTX, bson.M{"_id": id, "userid": claims.GetUserId()}).Decode(&report)
+	report, err = r.getReportModel(id, claims, PermissionRead)
+	return withoutSecrets(report), err
+}
+
+// getReportModel retrieves a report the user can read.
+// Returns ErrForbidden, if the user lacks the given permission.
+func (r *Client) getReportModel(id string, claims jwt.Token, permission Permission) (report lib.Report, err error) {
+	err = Reports().FindOne(CTX, bson.M{"$and": []bson.M{{"_id": id}, permissionFilter(claims, PermissionRead)}}).Decode(&report)
 	if err != nil {
 		return lib.Report{}, err
 	}
+	if !hasPermission(report, claims, permission) {
+		return lib.Report{}, ErrForbidden
+	}
+	report.Shared = report.UserId != claims.GetUserId()
 	return
 }
 
-// GetReportModels retrieves a list of reports from the MongoDB database based on the provided authentication token and query arguments.
+// GetReportModels retrieves a page of reports from the MongoDB database based on the provided authentication token and query arguments.
 //
 // Parameters:
 // - authTokenString: A string representing the authentication token.
-// - args: A map of query arguments, including limit, offset, order, and search.
+// - args: A map of query arguments, including limit, offset, order, search and the filters templateName, hasCron,
+// createdSince, createdUntil, updatedSince, updatedUntil and lastRunStatus.
 // - admin: A boolean indicating whether the retrieval is performed by an admin.
 //
 // Returns:
-// - reports: A slice of Report structs representing the retrieved reports.
-// - err: An error if the operation fails.
-func (r *Client) GetReportModels(authTokenString string, args map[string][]string, admin bool) (reports []lib.Report, err error) {
+// - page: The retrieved reports with the total count of matching reports.
+// - err: ErrValidation if the arguments are invalid, another error if the operation fails.
+func (r *Client) GetReportModels(authTokenString string, args map[string][]string, admin bool) (page lib.ReportPage, err error) {
 	claims, err := jwt.Parse(authTokenString)
 	if err != nil {
 		return
 	}
-	opt := options.Find()
-	for arg, value := range args {
-		if arg == "limit" {
-			limit, _ := strconv.ParseInt(value[0], 10, 64)
-			opt.SetLimit(limit)
-		}
-		if arg == "offset" {
-			skip, _ := strconv.ParseInt(value[0], 10, 64)
-			opt.SetSkip(skip)
-		}
-		if arg == "order" {
-			ord := strings.Split(value[0], ":")
-			order := 1
-			if ord[1] == "desc" {
-				order = -1
-			}
-			opt.SetSort(bson.M{ord[0]: int64(order)})
-		}
+	opt, err := reportListOptions(args)
+	if err != nil {
+		return
 	}
-	var cur *mongo.Cursor
-	req := bson.M{"userid": claims.GetUserId()}
-	if val, ok := args["search"]; ok {
-		req = bson.M{"userid": claims.GetUserId(), "_id": bson.RegEx{Pattern: val[0], Options: "i"}}
+	filters, err := reportListFilters(args)
+	if err != nil {
+		return
 	}
-	if admin {
-		req = bson.M{}
+	if !admin {
+		filters = append(filters, permissionFilter(claims, PermissionRead))
+	} else if val, ok := args["userId"]; ok {
+		// admins may filter by owner
+		filters = append(filters, bson.M{"userid": bson.M{"$in": val}})
 	}
-	cur, err = Reports().Find(CTX, req, opt)
+	req := bson.M{}
+	if len(filters) > 0 {
+		req = bson.M{"$and": filters}
+	}
+	page.Total, err = Reports().CountDocuments(CTX, req)
 	if err != nil {
-		return nil, err
+		return
+	}
+	if opt.Limit != nil {
+		page.Limit = *opt.Limit
 	}
+	if opt.Skip != nil {
+		page.Offset = *opt.Skip
+	}
+	cur, err := Reports().Find(CTX, req, opt)
+	if err != nil {
+		return
+	}
+	page.Data = []lib.Report{}
 	for cur.Next(CTX) {
 		// create a value into which the single document can be decoded
 		var elem lib.Report
 		err = cur.Decode(&elem)
 		if err != nil {
-			return nil, err
+			return
 		}
-		reports = append(reports, elem)
+		elem.Shared = elem.UserId != claims.GetUserId()
+		page.Data = append(page.Data, withoutSecrets(elem))
 	}
 	return
 }
@@ -630,35 +864,57 @@ func (r *Client) RunScheduler(ctx context.Context) error {
 					util.Logger.Error("could not decode report", "error", err)
 					continue
 				}
-				util.Logger.Info("creating scheduled report file for " + report.Id)
-				var token jwt.Token
-				token, _, err = jwt.ExchangeUserToken(
-					r.Config.Keycloak.Url,
-					r.Config.Keycloak.ClientId,
-					r.Config.Keycloak.ClientSecret,
-					report.UserId,
-				)
-				if err != nil {
-					util.Logger.Error("could not exchange user token", "error", err)
-					continue
-				}
-				var reportFileId string
-				_, reportFileId, err = r.CreateReportFile(report, token.Token) // already calculates and saves next schedule
-				if err != nil {
-					util.Logger.Error("could not create report file", "error", err)
-					continue
-				}
-				_, err = r.EmailReport(reportFileId, report, token.Token)
-				if err != nil {
-					util.Logger.Error("could not email report", "error", err)
-					continue
-				}
+				r.executeReport(report, AuditActorScheduler)
 			}
 		}
 
 	}
 }
 
+// executeReport runs a report on behalf of its owner and records the run in the audit log.
+// It is the common execution path of scheduled and triggered runs.
+func (r *Client) executeReport(report lib.Report, actor string) {
+	util.Logger.Info("creating report file for "+report.Id, "actor", actor)
+	reportFileId, err := r.runReport(report)
+	if reportFileId != "" {
+		if auditErr := r.RecordAudit(lib.AuditEntry{Actor: actor, Action: AuditActionReportRun, ReportId: report.Id, FileId: reportFileId}); auditErr != nil {
+			util.Logger.Error("could not record audit entry", "error", auditErr)
+		}
+	}
+	if err != nil {
+		util.Logger.Error("could not run report "+report.Id, "actor", actor, "error", err)
+	}
+}
+
+// runReport creates a report file on behalf of the report owner and emails it to the receivers of the report.
+//
+// Parameters:
+// - report: The report to run.
+//
+// Returns:
+// - reportFileId: The ID of the created report file.
+// - err: An error if the operation fails.
+func (r *Client) runReport(report lib.Report) (reportFileId string, err error) {
+	token, _, err := jwt.ExchangeUserToken(
+		r.Config.Keycloak.Url,
+		r.Config.Keycloak.ClientId,
+		r.Config.Keycloak.ClientSecret,
+		report.UserId,
+	)
+	if err != nil {
+		return "", fmt.Errorf("could not exchange user token: %w", err)
+	}
+	_, reportFileId, err = r.CreateReportFile(report, token.Token) // already calculates and saves next schedule
+	if err != nil {
+		return "", fmt.Errorf("could not create report file: %w", err)
+	}
+	_, err = r.DeliverReportFile(report, reportFileId, token.Token, true)
+	if err != nil {
+		return reportFileId, fmt.Errorf("could not deliver report: %w", err)
+	}
+	return
+}
+
 // EmailReport sends the specified report file to the email adrdesses specified in the report
 //
 // Parameters:
@@ -670,13 +926,46 @@ func (r *Client) RunScheduler(ctx context.Context) error {
 // - sent: true if an email has been sent, false otherwise
 // - err: An error if the operation fails.
 func (r *Client) EmailReport(reportFileId string, report lib.Report, token string) (sent bool, err error) {
-	if len(report.EmailReceivers) == 0 {
-		return false, nil
+	sent, _, err = r.emailReport(reportFileId, report, token)
+	return
+}
+
+// emailReport sends the report file and the other files of its run like EmailReport and returns,
+// whether the files were attached, zipped or linked. Different modes of several files are joined by comma.
+func (r *Client) emailReport(reportFileId string, report lib.Report, token string) (sent bool, mode string, err error) {
+	if !hasRecipients(report) {
+		return false, "", nil
+	}
+	if r.Mailer == nil {
+		return false, "", errors.New("mail is not configured")
 	}
-	b, contentType, fileTypeExtension, err := r.DownloadReportFile(report.Id, reportFileId, token)
+	// the stored file record holds the resolved period and values
+	stored, file, err := r.storedReportFile(report.Id, reportFileId)
 	if err != nil {
-		return false, err
+		return false, "", err
+	}
+	var attachments []mailer.Attachment
+	var linkedFiles []lib.ReportFile
+	var modes []string
+	for _, runFile := range runFiles(stored, file) {
+		b, contentType, fileTypeExtension, downloadErr := r.DownloadReportFile(report.Id, runFile.Id, token)
+		if downloadErr != nil {
+			return false, "", downloadErr
+		}
+		attachment, fileMode, attachmentErr := r.emailAttachment(report, runFile.Id+"."+fileTypeExtension, b, contentType)
+		if attachmentErr != nil {
+			return false, "", attachmentErr
+		}
+		if !slices.Contains(modes, fileMode) {
+			modes = append(modes, fileMode)
+		}
+		if attachment != nil {
+			attachments = append(attachments, *attachment)
+		} else {
+			linkedFiles = append(linkedFiles, runFile)
+		}
 	}
+	mode = strings.Join(modes, ",")
 	subject := report.EmailSubject
 	if len(subject) == 0 {
 		subject = r.Config.Mail.Subject
@@ -685,44 +974,43 @@ func (r *Client) EmailReport(reportFileId string, report lib.Report, token strin
 	if len(text) == 0 {
 		text = r.Config.Mail.Text
 	}
-	email := lib.SendRequest{
-		Bcc: report.EmailReceivers,
-		From: lib.FromTo{
+	data := r.emailTemplateData(stored, file, usesDownloadLink(subject, text, report.EmailHTML))
+	content, err := renderEmail(subject, text, report.EmailHTML, data)
+	if err != nil {
+		return false, mode, fmt.Errorf("could not render email templates: %w", err)
+	}
+	for _, linkedFile := range linkedFiles {
+		link := data.DownloadLink
+		if linkedFile.Id != file.Id || link == "" {
+			link, err = r.downloadLink(stored, linkedFile.Id)
+			if err != nil {
+				return false, mode, fmt.Errorf("report file exceeds the attachment size limit and could not be linked: %w", err)
+			}
+		}
+		content = appendDownloadLink(content, link)
+	}
+	email := mailer.Message{
+		From: mailer.Address{
 			Email: r.Config.Mail.From,
 		},
-		Attachments: []struct {
-			// Base64-encoded string of the file content
-			// required: true
-			// example: iVBORw0KGgoAAAANSUhEUgAAAEEAAAA8CAMAAAAOlSdoAAAACXBIWXMAAAHrAAAB6wGM2bZBAAAAS1BMVEVHcEwRfnUkZ2gAt4UsSF8At4UtSV4At4YsSV4At4YsSV8At4YsSV4At4YsSV4sSV4At4YsSV4At4YtSV4At4YsSV4At4YtSV8At4YsUWYNAAAAGHRSTlMAAwoXGiktRE5dbnd7kpOlr7zJ0d3h8PD8PCSRAAACWUlEQVR42pXT4ZaqIBSG4W9rhqQYocG+/ys9Y0Z0Br+x3j8zaxUPewFh65K+7yrIMeIY4MT3wPfEJCidKXEMnLaVkxDiELiMz4WEOAZSFghxBIypCOlKiAMgXfIqTnBgSm8CIQ6BImxEUxEckClVQiHGj4Ba4AQHikAIClwTE9KtIghAhUJwoLkmLnCiAHJLRKgIMsEtVUKbBUIwoAg2C4QgQBE6l4VCnApBgSKYLLApCnCa0+96AEMW2BQcmC+Pr3nfp7o5Exy49gIADcIqUELGfeA+bp93LmAJp8QJoEcN3C7NY3sbVANixMyI0nku20/n5/ZRf3KI2k6JEDWQtxcbdGuAqu3TAXG+/799Oyyas1B1MnMiA+XyxHp9q0PUKGPiRAau1fZbLRZV09wZcT8/gHk8QQAxXn8VgaDqcUmU6O/r28nbVwXAqca2mRNtPAF5+zoP2MeN9Fy4NgC6RfcbgE7XITBRYTtOE3U3C2DVff7pk+PkUxgAbvtnPXJaD6DxulMLwOhPS/M3MQkgg1ZFrIXnmfaZoOfpKiFgzeZD/WuKqQEGrfJYkyWf6vlG3xUgTuscnkNkQsb599q124kdpMUjCa/XARHs1gZymVtGt3wLkiFv8rUgTxitYCex5EVGec0Y9VmoDTFBSQte2TfXGXlf7hbdaUM9Sk7fisEN9qfBBTK+FZcvM9fQSdkl2vj4W2oX/bRogO3XasiNH7R0eW7fgRM834ImTg+Lg6BEnx4vz81rhr+MYPBBQg1v8GndEOrthxaCTxNAOut8WKLGZQl+MPz88Q9tAO/hVuSeqQAAAABJRU5ErkJggg==
-			Content string
-			// Filename
-			// required: true
-			// example: mailpit.png
-			Filename string
-			// Optional Content Type for the the attachment.
-			// If this field is not set (or empty) then the content type is automatically detected.
-			// required: false
-			// example: image/png
-			ContentType string
-			// Optional Content-ID (`cid`) for attachment.
-			// If this field is set then the file is attached inline.
-			// required: false
-			// example: mailpit-logo
-			ContentID string
-		}{{
-			Content:     base64.StdEncoding.EncodeToString(b),
-			ContentType: contentType,
-			Filename:    reportFileId + "." + fileTypeExtension,
-		}},
-		Subject: subject,
-		Text:    text,
-		HTML:    report.EmailHTML,
-	}
-	_, err = email.Send(r.Config.Mail.MailpitUrl)
-	if err != nil {
-		return false, err
-	}
-	return true, nil
+		Attachments: attachments,
+		Subject:     content.Subject,
+		Text:        content.Text,
+		HTML:        content.HTML,
+	}
+	if report.ReplyTo != nil {
+		email.ReplyTo = []mailer.Address{{Name: report.ReplyTo.Name, Email: report.ReplyTo.Email}}
+	}
+	// relays limit the recipients per email, failed parts do not stop the remaining ones
+	var errs []error
+	for _, message := range splitRecipients(report, email, r.Config.Mail.MaxRecipients) {
+		if sendErr := r.Mailer.Send(message); sendErr != nil {
+			errs = append(errs, sendErr)
+		} else {
+			sent = true
+		}
+	}
+	return sent, mode, errors.Join(errs...)
 }
 
 func calculateNextSchedule(r lib.Report) (t *time.Time, err error) {
@@ -731,7 +1019,10 @@ func calculateNextSchedule(r lib.Report) (t *time.Time, err error) {
 	}
 	schedule, err := cron.NewParser(cron.Minute | cron.Hour | cron.Dom | cron.Month | cron.Dow).Parse(r.Cron)
 	if err != nil {
-		return nil, err
+		return nil, fmt.Errorf("%w: invalid cron %q: %s", ErrValidation, r.Cron, err.Error())
+	}
+	if r.Paused {
+		return nil, nil
 	}
 	ts := schedule.Next(time.Now())
 	return &ts, err
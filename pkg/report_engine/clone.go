@@ -0,0 +1,124 @@
+/*
+ * Copyright 2025 InfAI (CC SES)
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package report_engine
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/SENERGY-Platform/reporting-service/lib"
+	"github.com/SENERGY-Platform/service-commons/pkg/jwt"
+)
+
+// CloneReport creates a new report owned by the requesting user from the definition of an existing report.
+// Files, permissions and versions are not cloned, webhooks and delivery targets only if the user owns the report, as
+// their secrets are not shared. Requires the read permission on the cloned report.
+//
+// Parameters:
+// - id: The ID of the report to clone.
+// - request: Overrides and id substitutions applied to the clone.
+// - authTokenString: The authentication token string.
+//
+// Returns:
+// - report: The saved clone with a new ID and schedule.
+// - err: ErrValidation if a substituted id is not used by any query, another error if the operation fails.
+func (r *Client) CloneReport(id string, request lib.CloneRequest, authTokenString string) (report lib.Report, err error) {
+	claims, err := jwt.Parse(authTokenString)
+	if err != nil {
+		return
+	}
+	source, err := r.getReportModel(id, claims, PermissionRead)
+	if err != nil {
+		return
+	}
+	report = reportDefinition(source)
+	if source.Shared {
+		// the secrets of webhooks and targets are not shared with other users
+		report.Webhooks, report.Targets = nil, nil
+	}
+	report.Name = source.Name + " (copy)"
+	if request.Name != nil {
+		report.Name = *request.Name
+	}
+	if request.Cron != nil {
+		report.Cron = *request.Cron
+	}
+	if request.EmailReceivers != nil {
+		report.EmailReceivers = *request.EmailReceivers
+	}
+	if request.EmailSubject != nil {
+		report.EmailSubject = *request.EmailSubject
+	}
+	if request.EmailText != nil {
+		report.EmailText = *request.EmailText
+	}
+	if request.EmailHTML != nil {
+		report.EmailHTML = *request.EmailHTML
+	}
+	if len(request.DeviceIds) > 0 || len(request.ServiceIds) > 0 {
+		used := map[string]bool{}
+		report.Data = substituteQueryIds(report.Data, request.DeviceIds, request.ServiceIds, used)
+		var unused []string
+		for oldId := range request.DeviceIds {
+			if !used["device:"+oldId] {
+				unused = append(unused, oldId)
+			}
+		}
+		for oldId := range request.ServiceIds {
+			if !used["service:"+oldId] {
+				unused = append(unused, oldId)
+			}
+		}
+		if len(unused) > 0 {
+			slices.Sort(unused)
+			return lib.Report{}, fmt.Errorf("%w: ids not used by any query: %s", ErrValidation, strings.Join(unused, ", "))
+		}
+	}
+	return r.SaveReportModel(report, authTokenString)
+}
+
+// substituteQueryIds returns a copy of data, in which the device and service ids of all queries are replaced.
+// Replaced ids are marked in used with the prefix "device:" or "service:".
+func substituteQueryIds(data map[string]lib.ReportObject, deviceIds map[string]string, serviceIds map[string]string, used map[string]bool) map[string]lib.ReportObject {
+	if data == nil {
+		return nil
+	}
+	result := make(map[string]lib.ReportObject, len(data))
+	for key, object := range data {
+		if object.Query != nil {
+			query := *object.Query
+			if query.DeviceId != nil {
+				if newId, ok := deviceIds[*query.DeviceId]; ok {
+					used["device:"+*query.DeviceId] = true
+					query.DeviceId = &newId
+				}
+			}
+			if query.ServiceId != nil {
+				if newId, ok := serviceIds[*query.ServiceId]; ok {
+					used["service:"+*query.ServiceId] = true
+					query.ServiceId = &newId
+				}
+			}
+			object.Query = &query
+		}
+		object.Fields = substituteQueryIds(object.Fields, deviceIds, serviceIds, used)
+		object.Children = substituteQueryIds(object.Children, deviceIds, serviceIds, used)
+		result[key] = object
+	}
+	return result
+}
@@ -0,0 +1,147 @@
+/*
+ * Copyright 2025 InfAI (CC SES)
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package report_engine
+
+import (
+	"errors"
+	"time"
+
+	"github.com/SENERGY-Platform/reporting-service/lib"
+	timescaleModels "github.com/SENERGY-Platform/timescale-wrapper/pkg/model"
+)
+
+// queryPreviousValues queries the comparison window selected by queryOptions.Compare.
+func (r *Client) queryPreviousValues(authToken string, query timescaleModels.QueriesRequestElement, queryOptions lib.QueryOptions, userId string, reportId string) (responseData []interface{}, err error) {
+	query.Time, err = shiftQueryTime(query.Time, *queryOptions.Compare, time.Now())
+	if err != nil {
+		return
+	}
+	return r.queryValues(authToken, query, queryOptions, userId, reportId)
+}
+
+// shiftQueryTime returns a copy of the query time, moved back by the given comparison period.
+// Relative windows ("last") are converted into absolute windows ending now.
+func shiftQueryTime(queryTime *timescaleModels.QueriesRequestElementTime, compare string, now time.Time) (shifted *timescaleModels.QueriesRequestElementTime, err error) {
+	if queryTime == nil {
+		return nil, errors.New("comparison requires a query time")
+	}
+	var start, end time.Time
+	switch {
+	case queryTime.Start != nil && queryTime.End != nil:
+		start, err = time.Parse(time.RFC3339, *queryTime.Start)
+		if err != nil {
+			return
+		}
+		end, err = time.Parse(time.RFC3339, *queryTime.End)
+		if err != nil {
+			return
+		}
+	case queryTime.Last != nil:
+		var duration time.Duration
+		duration, err = ParseDuration(*queryTime.Last)
+		if err != nil {
+			return
+		}
+		end = now
+		start = end.Add(-duration)
+	default:
+		return nil, errors.New("comparison requires a query with start and end or last")
+	}
+	switch compare {
+	case "previousPeriod":
+		duration := end.Sub(start)
+		start, end = start.Add(-duration), end.Add(-duration)
+	case "previousWeek":
+		start, end = start.AddDate(0, 0, -7), end.AddDate(0, 0, -7)
+	case "previousMonth":
+		start, end = start.AddDate(0, -1, 0), end.AddDate(0, -1, 0)
+	case "previousYear":
+		start, end = start.AddDate(-1, 0, 0), end.AddDate(-1, 0, 0)
+	default:
+		return nil, errors.New("unknown comparison " + compare)
+	}
+	startString, endString := start.Format(time.RFC3339), end.Format(time.RFC3339)
+	return &timescaleModels.QueriesRequestElementTime{Start: &startString, End: &endString}, nil
+}
+
+// compareValues combines the current and previous result of a query leaf.
+// Arrays are compared element by element, delta values are nil for non-numeric values.
+func compareValues(current interface{}, previous interface{}) map[string]interface{} {
+	result := map[string]interface{}{
+		"current":  current,
+		"previous": previous,
+	}
+	currentSlice, currentIsSlice := current.([]interface{})
+	previousSlice, previousIsSlice := previous.([]interface{})
+	if currentIsSlice || previousIsSlice {
+		length := max(len(currentSlice), len(previousSlice))
+		deltas := make([]interface{}, length)
+		deltaPercents := make([]interface{}, length)
+		for i := 0; i < length; i++ {
+			var c, p interface{}
+			if i < len(currentSlice) {
+				c = currentSlice[i]
+			}
+			if i < len(previousSlice) {
+				p = previousSlice[i]
+			}
+			deltas[i], deltaPercents[i] = delta(c, p)
+		}
+		result["delta"] = deltas
+		result["deltaPercent"] = deltaPercents
+		return result
+	}
+	result["delta"], result["deltaPercent"] = delta(current, previous)
+	return result
+}
+
+func delta(current interface{}, previous interface{}) (delta interface{}, deltaPercent interface{}) {
+	c, ok := toFloat(current)
+	if !ok {
+		return nil, nil
+	}
+	p, ok := toFloat(previous)
+	if !ok {
+		return nil, nil
+	}
+	delta = c - p
+	if p != 0 {
+		deltaPercent = (c - p) / p * 100
+	}
+	return
+}
+
+func toFloat(value interface{}) (float64, bool) {
+	switch v := value.(type) {
+	case float64:
+		return v, true
+	case float32:
+		return float64(v), true
+	case int:
+		return float64(v), true
+	case int64:
+		return float64(v), true
+	}
+	return 0, false
+}
+
+func firstValue(values []interface{}) interface{} {
+	if len(values) == 0 {
+		return nil
+	}
+	return values[0]
+}
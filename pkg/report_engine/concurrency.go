@@ -0,0 +1,73 @@
+/*
+ * Copyright 2025 InfAI (CC SES)
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package report_engine
+
+import (
+	"errors"
+	"time"
+
+	"github.com/SENERGY-Platform/reporting-service/lib"
+	"github.com/globalsign/mgo/bson"
+	mongobson "go.mongodb.org/mongo-driver/bson"
+)
+
+var ErrConflict = errors.New("report has been modified in the meantime")
+
+// revisionFilter matches a report in the given revision. Reports saved before revisions were introduced have none.
+func revisionFilter(id string, revision int) bson.M {
+	if revision == 0 {
+		return bson.M{"_id": id, "revision": bson.M{"$in": []interface{}{0, nil}}}
+	}
+	return bson.M{"_id": id, "revision": revision}
+}
+
+// definitionUpdate sets all fields of a report except owner, permissions, files and run status, which are updated separately.
+func definitionUpdate(report lib.Report) (update bson.M, err error) {
+	raw, err := mongobson.Marshal(report)
+	if err != nil {
+		return
+	}
+	set := bson.M{}
+	err = mongobson.Unmarshal(raw, &set)
+	if err != nil {
+		return
+	}
+	for _, key := range []string{"_id", "userid", "permissions", "reportfiles", "createdat", "lastrun", "triggeredat"} {
+		delete(set, key)
+	}
+	return bson.M{"$set": set}, nil
+}
+
+// appendReportFiles adds the files of a run to a report and sets its next schedule without touching the definition.
+func appendReportFiles(reportId string, files []lib.ReportFile, scheduledFor *time.Time) (err error) {
+	// reports without files may have stored null, which can not be pushed to
+	_, err = Reports().UpdateOne(CTX, bson.M{"_id": reportId, "reportfiles": nil}, bson.M{"$set": bson.M{"reportfiles": []lib.ReportFile{}}})
+	if err != nil {
+		return
+	}
+	_, err = Reports().UpdateOne(CTX, bson.M{"_id": reportId}, bson.M{
+		"$push": bson.M{"reportfiles": bson.M{"$each": files}},
+		"$set":  bson.M{"scheduledfor": scheduledFor},
+	})
+	return
+}
+
+// removeReportFile removes a file from a report without touching the definition.
+func removeReportFile(reportId string, fileId string) (err error) {
+	_, err = Reports().UpdateOne(CTX, bson.M{"_id": reportId}, bson.M{"$pull": bson.M{"reportfiles": bson.M{"id": fileId}}})
+	return
+}
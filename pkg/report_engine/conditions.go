@@ -0,0 +1,179 @@
+/*
+ * Copyright 2025 InfAI (CC SES)
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package report_engine
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"slices"
+	"strings"
+
+	"github.com/SENERGY-Platform/reporting-service/lib"
+)
+
+var conditionOperators = []string{"<", "<=", ">", ">=", "==", "!="}
+
+// prepareConditions validates the delivery conditions of a report and sets the defaults.
+func prepareConditions(conditions *lib.DeliveryConditions) error {
+	if conditions == nil {
+		return nil
+	}
+	if len(conditions.Rules) == 0 {
+		return fmt.Errorf("%w: delivery conditions require at least one rule", ErrValidation)
+	}
+	switch conditions.Match {
+	case "":
+		conditions.Match = lib.ConditionMatchAny
+	case lib.ConditionMatchAny, lib.ConditionMatchAll:
+	default:
+		return fmt.Errorf("%w: invalid condition match %q", ErrValidation, conditions.Match)
+	}
+	for _, rule := range conditions.Rules {
+		if err := validateConditionRule(rule); err != nil {
+			return err
+		}
+	}
+	switch conditions.Otherwise {
+	case "":
+		conditions.Otherwise = lib.ConditionActionSkip
+	case lib.ConditionActionSkip:
+	case lib.ConditionActionRedirect:
+		if len(conditions.RedirectTo) == 0 {
+			return fmt.Errorf("%w: redirected delivery requires recipients", ErrValidation)
+		}
+	default:
+		return fmt.Errorf("%w: invalid condition action %q", ErrValidation, conditions.Otherwise)
+	}
+	return prepareRecipientList(conditions.RedirectTo)
+}
+
+func validateConditionRule(rule lib.ConditionRule) error {
+	if rule.Path == "" {
+		return fmt.Errorf("%w: condition rule without path", ErrValidation)
+	}
+	if !slices.Contains(conditionOperators, rule.Operator) {
+		return fmt.Errorf("%w: invalid condition operator %q", ErrValidation, rule.Operator)
+	}
+	if _, numeric := toFloat(rule.Value); !numeric && rule.Operator != "==" && rule.Operator != "!=" {
+		return fmt.Errorf("%w: condition operator %q requires a numeric value", ErrValidation, rule.Operator)
+	}
+	return nil
+}
+
+// evaluateConditions evaluates the delivery conditions against the resolved report data.
+// Without conditions, nil is returned and the file is delivered.
+func evaluateConditions(conditions *lib.DeliveryConditions, data map[string]interface{}) (outcome *lib.ConditionOutcome, err error) {
+	if conditions == nil {
+		return nil, nil
+	}
+	// the resolved data contains structs of the drivers, their json representation is evaluated
+	b, err := json.Marshal(data)
+	if err != nil {
+		return
+	}
+	var normalized interface{}
+	err = json.Unmarshal(b, &normalized)
+	if err != nil {
+		return
+	}
+	outcome = &lib.ConditionOutcome{}
+	for _, rule := range conditions.Rules {
+		if matchRule(rule, normalized) {
+			outcome.Matched = append(outcome.Matched, fmt.Sprintf("%s %s %v", rule.Path, rule.Operator, rule.Value))
+		}
+	}
+	if conditions.Match == lib.ConditionMatchAll {
+		outcome.Met = len(outcome.Matched) == len(conditions.Rules)
+	} else {
+		outcome.Met = len(outcome.Matched) > 0
+	}
+	switch {
+	case outcome.Met:
+		outcome.Action = lib.ConditionActionDeliver
+	case conditions.Otherwise == lib.ConditionActionRedirect:
+		outcome.Action = lib.ConditionActionRedirect
+	default:
+		outcome.Action = lib.ConditionActionSkip
+	}
+	return
+}
+
+// matchRule checks if any value selected by the path of the rule matches.
+func matchRule(rule lib.ConditionRule, data interface{}) bool {
+	return slices.ContainsFunc(selectPath(data, strings.Split(rule.Path, ".")), func(value interface{}) bool {
+		return matchCondition(value, rule.Operator, rule.Value)
+	})
+}
+
+// selectPath returns all values at the path, "*" selects all elements of a list or object.
+func selectPath(value interface{}, path []string) []interface{} {
+	if len(path) == 0 {
+		return []interface{}{value}
+	}
+	var children []interface{}
+	switch v := value.(type) {
+	case map[string]interface{}:
+		if path[0] == "*" {
+			for _, child := range v {
+				children = append(children, child)
+			}
+		} else if child, ok := v[path[0]]; ok {
+			children = append(children, child)
+		}
+	case []interface{}:
+		if path[0] == "*" {
+			children = v
+		}
+	}
+	var values []interface{}
+	for _, child := range children {
+		values = append(values, selectPath(child, path[1:])...)
+	}
+	return values
+}
+
+// matchCondition compares value with expected. Numbers are compared numerically, other values by equality.
+func matchCondition(value interface{}, operator string, expected interface{}) bool {
+	expected = plainDocuments(expected) // compared with the JSON normalized data
+	a, aNumeric := toFloat(value)
+	b, bNumeric := toFloat(expected)
+	if aNumeric && bNumeric {
+		switch operator {
+		case "<":
+			return a < b
+		case "<=":
+			return a <= b
+		case ">":
+			return a > b
+		case ">=":
+			return a >= b
+		case "==":
+			return a == b
+		case "!=":
+			return a != b
+		}
+		return false
+	}
+	switch operator {
+	case "==":
+		return reflect.DeepEqual(value, expected)
+	case "!=":
+		return !reflect.DeepEqual(value, expected)
+	}
+	return false
+}
@@ -0,0 +1,153 @@
+/*
+ * Copyright 2025 InfAI (CC SES)
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package report_engine
+
+import (
+	"errors"
+	"slices"
+	"testing"
+
+	"github.com/SENERGY-Platform/reporting-service/lib"
+	jsreportModels "github.com/SENERGY-Platform/reporting-service/pkg/apis/jsreport/models"
+)
+
+func TestValidateConditionRule(t *testing.T) {
+	for name, test := range map[string]struct {
+		rule  lib.ConditionRule
+		valid bool
+	}{
+		"numeric":               {rule: lib.ConditionRule{Path: "total", Operator: ">", Value: 100.0}, valid: true},
+		"integer":               {rule: lib.ConditionRule{Path: "total", Operator: "<=", Value: 100}, valid: true},
+		"string equality":       {rule: lib.ConditionRule{Path: "status", Operator: "==", Value: "ok"}, valid: true},
+		"null inequality":       {rule: lib.ConditionRule{Path: "status", Operator: "!=", Value: nil}, valid: true},
+		"missing path":          {rule: lib.ConditionRule{Operator: ">", Value: 1.0}},
+		"unknown operator":      {rule: lib.ConditionRule{Path: "total", Operator: "=>", Value: 1.0}},
+		"non numeric less than": {rule: lib.ConditionRule{Path: "status", Operator: "<", Value: "ok"}},
+	} {
+		err := validateConditionRule(test.rule)
+		if test.valid && err != nil {
+			t.Errorf("%s: unexpected error %v", name, err)
+		}
+		if !test.valid && !errors.Is(err, ErrValidation) {
+			t.Errorf("%s: expected validation error, got %v", name, err)
+		}
+	}
+}
+
+func TestMatchRule(t *testing.T) {
+	// data as normalized by evaluateConditions
+	data := map[string]interface{}{
+		"total":  120.0,
+		"status": "ok",
+		"devices": []interface{}{
+			map[string]interface{}{"availability": map[string]interface{}{"availability_percent": 90.0}},
+			map[string]interface{}{"availability": map[string]interface{}{"availability_percent": 99.5}},
+		},
+		"groups": map[string]interface{}{
+			"a": map[string]interface{}{"total": 5.0},
+			"b": map[string]interface{}{"total": 50.0},
+		},
+	}
+	for _, test := range []struct {
+		rule lib.ConditionRule
+		want bool
+	}{
+		{lib.ConditionRule{Path: "total", Operator: ">", Value: 100.0}, true},
+		{lib.ConditionRule{Path: "total", Operator: ">", Value: 200.0}, false},
+		{lib.ConditionRule{Path: "total", Operator: "==", Value: 120}, true},
+		{lib.ConditionRule{Path: "total", Operator: ">=", Value: 120}, true},
+		{lib.ConditionRule{Path: "total", Operator: "<", Value: 120}, false},
+		{lib.ConditionRule{Path: "total", Operator: "!=", Value: 120.0}, false},
+		{lib.ConditionRule{Path: "status", Operator: "==", Value: "ok"}, true},
+		{lib.ConditionRule{Path: "status", Operator: "!=", Value: "ok"}, false},
+		{lib.ConditionRule{Path: "status", Operator: "<", Value: 1.0}, false},
+		{lib.ConditionRule{Path: "devices.*.availability.availability_percent", Operator: "<", Value: 95.0}, true},
+		{lib.ConditionRule{Path: "devices.*.availability.availability_percent", Operator: "<", Value: 80.0}, false},
+		{lib.ConditionRule{Path: "groups.*.total", Operator: ">", Value: 10.0}, true},
+		{lib.ConditionRule{Path: "groups.a.total", Operator: ">", Value: 10.0}, false},
+		{lib.ConditionRule{Path: "devices.0.availability", Operator: "==", Value: nil}, false},
+		{lib.ConditionRule{Path: "missing", Operator: "==", Value: nil}, false},
+	} {
+		if got := matchRule(test.rule, data); got != test.want {
+			t.Errorf("%s %s %v = %v, want %v", test.rule.Path, test.rule.Operator, test.rule.Value, got, test.want)
+		}
+	}
+}
+
+func TestEvaluateConditions(t *testing.T) {
+	percent := 90.0
+	data := map[string]interface{}{
+		"total": 120.0,
+		// structs of the drivers are evaluated by their JSON representation
+		"devices": []jsreportModels.DeviceState{{Availability: jsreportModels.Availability{AvailabilityPercent: &percent}}},
+	}
+	low := lib.ConditionRule{Path: "devices.*.availability.availability_percent", Operator: "<", Value: 95.0}
+	high := lib.ConditionRule{Path: "total", Operator: ">", Value: 1000.0}
+	for name, test := range map[string]struct {
+		conditions *lib.DeliveryConditions
+		want       *lib.ConditionOutcome
+	}{
+		"no conditions": {},
+		"any met": {
+			conditions: &lib.DeliveryConditions{Match: lib.ConditionMatchAny, Rules: []lib.ConditionRule{low, high}, Otherwise: lib.ConditionActionSkip},
+			want:       &lib.ConditionOutcome{Met: true, Action: lib.ConditionActionDeliver, Matched: []string{"devices.*.availability.availability_percent < 95"}},
+		},
+		"all not met": {
+			conditions: &lib.DeliveryConditions{Match: lib.ConditionMatchAll, Rules: []lib.ConditionRule{low, high}, Otherwise: lib.ConditionActionSkip},
+			want:       &lib.ConditionOutcome{Action: lib.ConditionActionSkip, Matched: []string{"devices.*.availability.availability_percent < 95"}},
+		},
+		"redirect": {
+			conditions: &lib.DeliveryConditions{Match: lib.ConditionMatchAny, Rules: []lib.ConditionRule{high}, Otherwise: lib.ConditionActionRedirect},
+			want:       &lib.ConditionOutcome{Action: lib.ConditionActionRedirect},
+		},
+	} {
+		got, err := evaluateConditions(test.conditions, data)
+		if err != nil {
+			t.Errorf("%s: unexpected error %v", name, err)
+			continue
+		}
+		if (got == nil) != (test.want == nil) {
+			t.Errorf("%s: outcome = %v, want %v", name, got, test.want)
+			continue
+		}
+		if got != nil && (got.Met != test.want.Met || got.Action != test.want.Action || !slices.Equal(got.Matched, test.want.Matched)) {
+			t.Errorf("%s: outcome = %+v, want %+v", name, *got, *test.want)
+		}
+	}
+}
+
+func TestPrepareConditions(t *testing.T) {
+	rule := lib.ConditionRule{Path: "total", Operator: ">", Value: 1.0}
+	conditions := &lib.DeliveryConditions{Rules: []lib.ConditionRule{rule}}
+	if err := prepareConditions(conditions); err != nil {
+		t.Fatal(err)
+	}
+	if conditions.Match != lib.ConditionMatchAny || conditions.Otherwise != lib.ConditionActionSkip {
+		t.Errorf("defaults = %q, %q", conditions.Match, conditions.Otherwise)
+	}
+	for name, invalid := range map[string]*lib.DeliveryConditions{
+		"no rules":                 {},
+		"unknown match":            {Match: "some", Rules: []lib.ConditionRule{rule}},
+		"invalid rule":             {Rules: []lib.ConditionRule{{Path: "total", Operator: "~"}}},
+		"unknown action":           {Rules: []lib.ConditionRule{rule}, Otherwise: "drop"},
+		"redirect without targets": {Rules: []lib.ConditionRule{rule}, Otherwise: lib.ConditionActionRedirect},
+	} {
+		if err := prepareConditions(invalid); !errors.Is(err, ErrValidation) {
+			t.Errorf("%s: expected validation error, got %v", name, err)
+		}
+	}
+}
@@ -6,6 +6,8 @@ import (
 	"time"
 
 	"github.com/SENERGY-Platform/reporting-service/pkg/util"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
 	"go.mongodb.org/mongo-driver/mongo"
 	"go.mongodb.org/mongo-driver/mongo/options"
 )
@@ -28,15 +30,85 @@ func InitDB(url string) {
 		util.Logger.Info("connected to database")
 	}
 	DB = client
+	if err == nil {
+		createIndexes(CTX)
+	}
+}
+
+// createIndexes creates the indexes required by the queries of the service. Errors are logged, since the service
+// works without indexes, only slower.
+func createIndexes(ctx context.Context) {
+	indexes := map[*mongo.Collection][]mongo.IndexModel{
+		// claim query of the event publisher
+		EventOutbox(): {{Keys: bson.D{{Key: "failedat", Value: 1}, {Key: "lockeduntil", Value: 1}, {Key: "createdat", Value: 1}}}},
+		// version numbers are unique per report, also for concurrent updates
+		ReportVersions(): {{Keys: bson.D{{Key: "reportid", Value: 1}, {Key: "version", Value: 1}}, Options: options.Index().SetUnique(true)}},
+	}
+	for collection, models := range indexes {
+		if _, err := collection.Indexes().CreateMany(ctx, models); err != nil {
+			util.Logger.Error("could not create indexes of collection "+collection.Name(), "error", err)
+		}
+	}
 }
 
 func Reports() *mongo.Collection {
 	return DB.Database("reporting").Collection("reports")
 }
 
+func FileShares() *mongo.Collection {
+	return DB.Database("reporting").Collection("file_shares")
+}
+
+func Audit() *mongo.Collection {
+	return DB.Database("reporting").Collection("audit")
+}
+
+func ReportVersions() *mongo.Collection {
+	return DB.Database("reporting").Collection("report_versions")
+}
+
+func ReportRuns() *mongo.Collection {
+	return DB.Database("reporting").Collection("report_runs")
+}
+
+func EventOutbox() *mongo.Collection {
+	return DB.Database("reporting").Collection("event_outbox")
+}
+
 func CloseDB() {
 	err := DB.Disconnect(CTX)
 	if err != nil {
 		panic("failed to disconnect database: " + err.Error())
 	}
 }
+
+// plainDocuments converts the documents and arrays of a value decoded into an interface{} to maps and slices, so they
+// are accessible by key and serialized as JSON objects. Order of keys is not kept.
+func plainDocuments(value interface{}) interface{} {
+	switch v := value.(type) {
+	case primitive.D:
+		result := make(map[string]interface{}, len(v))
+		for _, element := range v {
+			result[element.Key] = plainDocuments(element.Value)
+		}
+		return result
+	case primitive.M:
+		return plainDocuments(map[string]interface{}(v))
+	case map[string]interface{}:
+		result := make(map[string]interface{}, len(v))
+		for key, element := range v {
+			result[key] = plainDocuments(element)
+		}
+		return result
+	case primitive.A:
+		return plainDocuments([]interface{}(v))
+	case []interface{}:
+		result := make([]interface{}, len(v))
+		for i, element := range v {
+			result[i] = plainDocuments(element)
+		}
+		return result
+	default:
+		return value
+	}
+}
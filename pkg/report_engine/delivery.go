@@ -0,0 +1,309 @@
+/*
+ * Copyright 2025 InfAI (CC SES)
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package report_engine
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"mime/multipart"
+	"net/http"
+	"net/textproto"
+	"slices"
+	"strconv"
+	"time"
+
+	"github.com/SENERGY-Platform/reporting-service/lib"
+	"github.com/SENERGY-Platform/reporting-service/pkg/util"
+	"github.com/SENERGY-Platform/service-commons/pkg/jwt"
+	"github.com/globalsign/mgo/bson"
+	"github.com/go-resty/resty/v2"
+	"github.com/google/uuid"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+const (
+	WebhookEventFileCreated = "report.file.created"
+
+	HeaderWebhookEvent     = "X-Reporting-Event"
+	HeaderWebhookTimestamp = "X-Reporting-Timestamp"
+	HeaderWebhookSignature = "X-Reporting-Signature"
+)
+
+var ErrDeliveryFailed = errors.New("delivery failed")
+
+// DeliverReportFile delivers a created report file to the webhooks and targets of the report and, if email is true,
+// to its email receivers. The outcome of every delivery is recorded as run of the report.
+// If the delivery conditions of the report were not met by the file, it is skipped or only emailed to the redirect recipients.
+//
+// Parameters:
+// - report: The report of the file.
+// - reportFileId: The ID of the file to deliver.
+// - token: Token of the report owner.
+// - email: Whether the file is emailed.
+//
+// Returns:
+// - run: The recorded run.
+// - err: ErrDeliveryFailed if any delivery failed, another error if the run could not be recorded.
+func (r *Client) DeliverReportFile(report lib.Report, reportFileId string, token string, email bool) (run lib.ReportRun, err error) {
+	run = lib.ReportRun{
+		Id:         uuid.New().String(),
+		ReportId:   report.Id,
+		FileId:     reportFileId,
+		CreatedAt:  time.Now(),
+		Deliveries: []lib.Delivery{},
+	}
+	stored, file, err := r.storedReportFile(report.Id, reportFileId)
+	if err != nil {
+		return
+	}
+	run.Condition = file.Condition
+	mailed, webhooks, targets := report, report.Webhooks, report.Targets
+	if run.Condition != nil && !run.Condition.Met {
+		webhooks, targets = nil, nil
+		if run.Condition.Action == lib.ConditionActionRedirect && report.Conditions != nil {
+			mailed.EmailReceivers, mailed.Recipients = nil, report.Conditions.RedirectTo
+		} else {
+			email = false
+		}
+	}
+	if email && hasRecipients(mailed) {
+		delivery := lib.Delivery{Channel: lib.DeliveryChannelEmail, Target: strconv.Itoa(recipientCount(mailed)) + " receivers", Attempts: 1}
+		var sendErr error
+		_, delivery.Mode, sendErr = r.emailReport(reportFileId, mailed, token)
+		run.Deliveries = append(run.Deliveries, finishDelivery(delivery, sendErr))
+	}
+	// webhooks and targets receive every file of the run separately
+	files := runFiles(stored, file)
+	for _, runFile := range files {
+		var fileId string
+		if len(files) > 1 {
+			fileId = runFile.Id
+		}
+		var content []byte
+		var contentType, ext string
+		var contentErr error
+		if len(targets) > 0 || slices.ContainsFunc(webhooks, func(webhook lib.Webhook) bool { return webhook.Mode == lib.WebhookModeFile }) {
+			content, contentType, ext, contentErr = r.DownloadReportFile(report.Id, runFile.Id, token)
+		}
+		if len(webhooks) > 0 {
+			notification, prepErr := r.prepareWebhookNotification(report, runFile.Id)
+			if prepErr == nil {
+				prepErr = contentErr
+			}
+			for _, webhook := range webhooks {
+				delivery := lib.Delivery{Channel: lib.DeliveryChannelWebhook, Target: webhook.Url, FileId: fileId}
+				if prepErr != nil {
+					run.Deliveries = append(run.Deliveries, finishDelivery(delivery, prepErr))
+					continue
+				}
+				var sendErr error
+				delivery.Attempts, delivery.StatusCode, sendErr = r.sendWebhook(webhook, notification, content, contentType, ext)
+				run.Deliveries = append(run.Deliveries, finishDelivery(delivery, sendErr))
+			}
+		}
+		for _, target := range targets {
+			delivery := lib.Delivery{Channel: target.Type, Attempts: 1, FileId: fileId}
+			deliverErr := contentErr
+			if deliverErr == nil {
+				delivery.Target, deliverErr = r.deliverToTarget(target, report, runFile.Id, content, contentType, ext, run.CreatedAt)
+			}
+			run.Deliveries = append(run.Deliveries, finishDelivery(delivery, deliverErr))
+		}
+	}
+	// skipped files are recorded to show the outcome of the conditions
+	if len(run.Deliveries) == 0 && run.Condition == nil {
+		return run, nil
+	}
+	_, err = ReportRuns().InsertOne(CTX, run)
+	if err != nil {
+		return
+	}
+	for _, delivery := range run.Deliveries {
+		if delivery.Status == lib.RunStatusFailed {
+			err = fmt.Errorf("%w: %s %s: %s", ErrDeliveryFailed, delivery.Channel, delivery.Target, delivery.Error)
+			event := fileEvent(EventRunFailed, stored, file)
+			event.Error = err.Error()
+			r.publishEvent(event)
+			return
+		}
+	}
+	return
+}
+
+// storedReportFile loads the stored report and the record of one of its files.
+// The record holds values of the run, which are not part of the report passed to the delivery.
+func (r *Client) storedReportFile(reportId string, reportFileId string) (stored lib.Report, file lib.ReportFile, err error) {
+	stored, err = findReport(reportId)
+	if err != nil {
+		return
+	}
+	index := slices.IndexFunc(stored.ReportFiles, func(file lib.ReportFile) bool { return file.Id == reportFileId })
+	if index < 0 {
+		return stored, file, errors.New("unknown report file " + reportFileId)
+	}
+	return stored, stored.ReportFiles[index], nil
+}
+
+// GetReportRuns lists the runs of a report, latest first. Requires the read permission.
+func (r *Client) GetReportRuns(reportId string, args map[string][]string, authTokenString string) (runs []lib.ReportRun, err error) {
+	claims, err := jwt.Parse(authTokenString)
+	if err != nil {
+		return
+	}
+	_, err = r.getReportModel(reportId, claims, PermissionRead)
+	if err != nil {
+		return
+	}
+	return findReportRuns(reportId, args)
+}
+
+func findReportRuns(reportId string, args map[string][]string) (runs []lib.ReportRun, err error) {
+	opt := options.Find().SetSort(bson.M{"createdat": -1})
+	if val, ok := args["limit"]; ok {
+		limit, _ := strconv.ParseInt(val[0], 10, 64)
+		opt.SetLimit(limit)
+	}
+	if val, ok := args["offset"]; ok {
+		skip, _ := strconv.ParseInt(val[0], 10, 64)
+		opt.SetSkip(skip)
+	}
+	cur, err := ReportRuns().Find(CTX, bson.M{"reportid": reportId}, opt)
+	if err != nil {
+		return
+	}
+	runs = []lib.ReportRun{}
+	err = cur.All(CTX, &runs)
+	return
+}
+
+func finishDelivery(delivery lib.Delivery, err error) lib.Delivery {
+	delivery.Time = time.Now()
+	delivery.Status = lib.RunStatusSuccess
+	if err != nil {
+		delivery.Status = lib.RunStatusFailed
+		delivery.Error = err.Error()
+	}
+	return delivery
+}
+
+// prepareWebhookNotification creates the notification of a report file including a download link, if sharing is configured.
+func (r *Client) prepareWebhookNotification(report lib.Report, reportFileId string) (notification lib.WebhookNotification, err error) {
+	stored, file, err := r.storedReportFile(report.Id, reportFileId)
+	if err != nil {
+		return
+	}
+	notification = lib.WebhookNotification{
+		Event:      WebhookEventFileCreated,
+		ReportId:   report.Id,
+		ReportName: report.Name,
+		FileId:     reportFileId,
+		Type:       file.Type,
+		CreatedAt:  file.CreatedAt,
+		RunId:      file.RunId,
+	}
+	if r.Config.Share.Secret.Value() != "" {
+		share, shareErr := r.createFileShare(stored, reportFileId, r.Config.Webhook.LinkTTL)
+		if shareErr != nil {
+			util.Logger.Error("could not create download link for webhook", "error", shareErr)
+		} else {
+			notification.DownloadUrl = share.Url
+			notification.ExpiresAt = &share.ExpiresAt
+		}
+	}
+	return
+}
+
+// sendWebhook posts the notification, and in file mode the file, to the webhook. Failed requests are retried.
+// The body is signed as HMAC-SHA256 of "<timestamp>.<body>" with the secret of the webhook.
+func (r *Client) sendWebhook(webhook lib.Webhook, notification lib.WebhookNotification, content []byte, contentType string, ext string) (attempts int, statusCode int, err error) {
+	body, err := json.Marshal(notification)
+	if err != nil {
+		return
+	}
+	bodyContentType := "application/json"
+	if webhook.Mode == lib.WebhookModeFile {
+		body, bodyContentType, err = webhookMultipart(body, notification.FileId+"."+ext, content, contentType)
+		if err != nil {
+			return
+		}
+	}
+	timestamp := strconv.FormatInt(time.Now().Unix(), 10)
+	mac := hmac.New(sha256.New, []byte(webhook.Secret))
+	mac.Write([]byte(timestamp + "."))
+	mac.Write(body)
+
+	client := resty.New().
+		SetTransport(r.outboundTransport()).
+		SetTimeout(r.Config.Webhook.Timeout).
+		SetRetryCount(r.Config.Webhook.Retries).
+		SetRetryWaitTime(r.Config.Webhook.RetryWait).
+		AddRetryCondition(func(resp *resty.Response, err error) bool {
+			return err != nil || resp.StatusCode() >= http.StatusInternalServerError || resp.StatusCode() == http.StatusTooManyRequests
+		})
+	resp, err := client.R().
+		SetHeader("Content-Type", bodyContentType).
+		SetHeader(HeaderWebhookEvent, notification.Event).
+		SetHeader(HeaderWebhookTimestamp, timestamp).
+		SetHeader(HeaderWebhookSignature, "sha256="+hex.EncodeToString(mac.Sum(nil))).
+		SetBody(body).
+		Post(webhook.Url)
+	if resp != nil {
+		attempts = resp.Request.Attempt
+		statusCode = resp.StatusCode()
+	}
+	if err != nil {
+		return
+	}
+	if resp.IsError() {
+		err = fmt.Errorf("unexpected status code %v", statusCode)
+	}
+	return
+}
+
+func webhookMultipart(notification []byte, filename string, content []byte, contentType string) (body []byte, bodyContentType string, err error) {
+	buf := &bytes.Buffer{}
+	w := multipart.NewWriter(buf)
+	part, err := w.CreatePart(textproto.MIMEHeader{
+		"Content-Disposition": {`form-data; name="notification"`},
+		"Content-Type":        {"application/json"},
+	})
+	if err != nil {
+		return
+	}
+	if _, err = part.Write(notification); err != nil {
+		return
+	}
+	part, err = w.CreatePart(textproto.MIMEHeader{
+		"Content-Disposition": {fmt.Sprintf(`form-data; name="file"; filename="%s"`, filename)},
+		"Content-Type":        {contentType},
+	})
+	if err != nil {
+		return
+	}
+	if _, err = part.Write(content); err != nil {
+		return
+	}
+	if err = w.Close(); err != nil {
+		return
+	}
+	return buf.Bytes(), w.FormDataContentType(), nil
+}
@@ -0,0 +1,274 @@
+/*
+ * Copyright 2025 InfAI (CC SES)
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package report_engine
+
+import (
+	"bytes"
+	"fmt"
+	htmlTemplate "html/template"
+	"slices"
+	"strings"
+	"text/template"
+	"time"
+
+	"github.com/SENERGY-Platform/reporting-service/lib"
+	"github.com/SENERGY-Platform/reporting-service/pkg/util"
+	timescaleModels "github.com/SENERGY-Platform/timescale-wrapper/pkg/model"
+)
+
+const periodDateLayout = "2006-01-02"
+
+// EmailTemplateData is available in the email subject, text and html of a report,
+// e.g. "Energy report {{.Period}}: {{.Data.total}} kWh".
+type EmailTemplateData struct {
+	ReportId     string
+	ReportName   string
+	RunTime      time.Time
+	Period       string // covered period of the queries, e.g. "2025-01-01 - 2025-01-31"
+	PeriodStart  *time.Time
+	PeriodEnd    *time.Time
+	FileId       string
+	FileType     string // MIME type of the report file, e.g. "application/pdf"
+	DownloadLink string // only set if file sharing is configured
+	Data         map[string]interface{}
+}
+
+// emailContent holds the rendered subject and bodies of an email.
+type emailContent struct {
+	Subject string
+	Text    string
+	HTML    string
+}
+
+// validateEmailTemplates renders the email templates of the report with sample data derived from the report data,
+// so that syntax errors and references to unknown variables, including unknown keys of Data, are reported before the
+// report is saved.
+func (r *Client) validateEmailTemplates(report lib.Report) error {
+	if usesDownloadLink(report.EmailSubject, report.EmailText, report.EmailHTML) && r.Config.Share.Secret.Value() == "" {
+		return fmt.Errorf("%w: email templates can not use DownloadLink, file sharing is not configured", ErrValidation)
+	}
+	now := time.Now()
+	data := EmailTemplateData{
+		ReportId:     report.Id,
+		ReportName:   report.Name,
+		RunTime:      now,
+		Period:       formatPeriod(&now, &now),
+		PeriodStart:  &now,
+		PeriodEnd:    &now,
+		FileId:       "sample",
+		FileType:     "application/pdf",
+		DownloadLink: "https://example.com",
+		Data:         sampleTemplateValues(report.Data),
+	}
+	_, err := renderEmail(report.EmailSubject, report.EmailText, report.EmailHTML, data)
+	if err != nil {
+		return fmt.Errorf("%w: %s", ErrValidation, err.Error())
+	}
+	return nil
+}
+
+// emailTemplateData collects the template data of a report file. A download link is only created, if requested by withLink.
+func (r *Client) emailTemplateData(report lib.Report, file lib.ReportFile, withLink bool) EmailTemplateData {
+	data := EmailTemplateData{
+		ReportId:    report.Id,
+		ReportName:  report.Name,
+		RunTime:     file.CreatedAt,
+		Period:      formatPeriod(file.PeriodStart, file.PeriodEnd),
+		PeriodStart: file.PeriodStart,
+		PeriodEnd:   file.PeriodEnd,
+		FileId:      file.Id,
+		FileType:    file.Type,
+		Data:        plainDocuments(file.Values).(map[string]interface{}),
+	}
+	if withLink && r.Config.Share.Secret.Value() != "" {
+		link, err := r.downloadLink(report, file.Id)
+		if err != nil {
+			util.Logger.Error("could not create download link for email", "error", err)
+		} else {
+			data.DownloadLink = link
+		}
+	}
+	return data
+}
+
+// downloadLink shares a report file for the default duration of shares.
+func (r *Client) downloadLink(report lib.Report, fileId string) (string, error) {
+	share, err := r.createFileShare(report, fileId, r.Config.Share.DefaultTTL)
+	return share.Url, err
+}
+
+func usesDownloadLink(templates ...string) bool {
+	return slices.ContainsFunc(templates, func(text string) bool { return strings.Contains(text, "DownloadLink") })
+}
+
+// renderEmail executes the subject and text as text templates and the html as html template.
+// Missing keys of Data are errors, so that emails do not contain "<no value>".
+func renderEmail(subject string, text string, html string, data EmailTemplateData) (content emailContent, err error) {
+	content.Subject, err = renderTextTemplate("emailSubject", subject, data)
+	if err != nil {
+		return
+	}
+	// line breaks would end the header
+	content.Subject = strings.Join(strings.Fields(content.Subject), " ")
+	content.Text, err = renderTextTemplate("emailText", text, data)
+	if err != nil {
+		return
+	}
+	if html == "" {
+		return
+	}
+	tmpl, err := htmlTemplate.New("emailHTML").Option("missingkey=error").Parse(html)
+	if err != nil {
+		return
+	}
+	var buf bytes.Buffer
+	err = tmpl.Execute(&buf, data)
+	content.HTML = buf.String()
+	return
+}
+
+func renderTextTemplate(name string, text string, data EmailTemplateData) (string, error) {
+	if text == "" {
+		return "", nil
+	}
+	tmpl, err := template.New(name).Option("missingkey=error").Parse(text)
+	if err != nil {
+		return "", err
+	}
+	var buf bytes.Buffer
+	err = tmpl.Execute(&buf, data)
+	return buf.String(), err
+}
+
+// formatPeriod formats the covered period as dates. The end of a period is exclusive,
+// so a period ending at midnight ends on the previous day.
+func formatPeriod(start *time.Time, end *time.Time) string {
+	if start == nil || end == nil {
+		return ""
+	}
+	last := *end
+	if last.After(*start) && last.Equal(last.Truncate(24*time.Hour)) {
+		last = last.Add(-time.Nanosecond)
+	}
+	if start.Format(periodDateLayout) == last.Format(periodDateLayout) {
+		return start.Format(periodDateLayout)
+	}
+	return start.Format(periodDateLayout) + " - " + last.Format(periodDateLayout)
+}
+
+// queryPeriod returns the span of the time windows of all queries of the report data.
+// Windows of queries are expected to be resolved already, relative windows end at now.
+func queryPeriod(data map[string]lib.ReportObject, now time.Time) (start *time.Time, end *time.Time) {
+	extend := func(s time.Time, e time.Time) {
+		if start == nil || s.Before(*start) {
+			start = &s
+		}
+		if end == nil || e.After(*end) {
+			end = &e
+		}
+	}
+	for _, value := range data {
+		if value.Query != nil && value.Query.Time != nil {
+			if s, e, ok := queryWindow(*value.Query.Time, now); ok {
+				extend(s, e)
+			}
+		}
+		if value.DeviceQuery != nil {
+			if s, e, err := getDeviceQueryWindow(*value.DeviceQuery, now); err == nil {
+				extend(s, e)
+			}
+		}
+		for _, children := range []map[string]lib.ReportObject{value.Fields, value.Children} {
+			if s, e := queryPeriod(children, now); s != nil {
+				extend(*s, *e)
+			}
+		}
+	}
+	return
+}
+
+// queryWindow resolves the time window of a query. Windows without start are skipped.
+func queryWindow(queryTime timescaleModels.QueriesRequestElementTime, now time.Time) (start time.Time, end time.Time, ok bool) {
+	end = now
+	if queryTime.End != nil {
+		parsed, err := time.Parse(time.RFC3339, *queryTime.End)
+		if err != nil {
+			return
+		}
+		end = parsed
+	}
+	switch {
+	case queryTime.Start != nil:
+		parsed, err := time.Parse(time.RFC3339, *queryTime.Start)
+		if err != nil {
+			return
+		}
+		start = parsed
+	case queryTime.Last != nil:
+		duration, err := ParseDuration(*queryTime.Last)
+		if err != nil {
+			return
+		}
+		start = end.Add(-duration)
+	default:
+		return
+	}
+	return start, end, true
+}
+
+// templateValues keeps the scalar values of the resolved report data, including nested objects like comparisons.
+// Lists are left out, they are only part of the report file.
+func templateValues(data map[string]interface{}) map[string]interface{} {
+	values := make(map[string]interface{})
+	for key, value := range data {
+		switch v := value.(type) {
+		case string, bool, int, int64, float32, float64, nil:
+			values[key] = v
+		case map[string]interface{}:
+			if nested := templateValues(v); len(nested) > 0 {
+				values[key] = nested
+			}
+		}
+	}
+	if len(values) == 0 {
+		return nil
+	}
+	return values
+}
+
+// sampleTemplateValues mirrors the structure of templateValues for the report data definition.
+func sampleTemplateValues(data map[string]lib.ReportObject) map[string]interface{} {
+	values := make(map[string]interface{})
+	for key, value := range data {
+		switch value.ValueType {
+		case "string", "int", "float", "float64":
+			switch {
+			case value.Value != nil:
+				values[key] = value.Value
+			case value.Query != nil && value.QueryOptions != nil && value.QueryOptions.Compare != nil:
+				values[key] = map[string]interface{}{"current": 0.0, "previous": 0.0, "delta": 0.0, "deltaPercent": 0.0}
+			case value.ValueType == "string":
+				values[key] = ""
+			default:
+				values[key] = 0.0
+			}
+		case "object":
+			values[key] = sampleTemplateValues(value.Fields)
+		}
+	}
+	return values
+}
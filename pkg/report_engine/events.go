@@ -0,0 +1,237 @@
+/*
+ * Copyright 2025 InfAI (CC SES)
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package report_engine
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"time"
+
+	"github.com/SENERGY-Platform/reporting-service/lib"
+	"github.com/SENERGY-Platform/reporting-service/pkg/apis/kafka"
+	"github.com/SENERGY-Platform/reporting-service/pkg/config"
+	"github.com/SENERGY-Platform/reporting-service/pkg/util"
+	"github.com/globalsign/mgo/bson"
+	"github.com/google/uuid"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+const (
+	EventFileCreated   = WebhookEventFileCreated
+	EventRunFailed     = "report.run.failed"
+	EventReportUpdated = "report.updated"
+	EventReportDeleted = "report.deleted"
+)
+
+// outboxEntry stores an event until it has been published, so that events survive outages of Kafka.
+type outboxEntry struct {
+	Id          string `bson:"_id"`
+	Topic       string
+	Event       lib.Event
+	CreatedAt   time.Time
+	LockedUntil *time.Time // set while an instance publishes the entry, or until the next attempt after a failure
+	Attempts    int
+	LastError   string
+	FailedAt    *time.Time // set when Kafka.OutboxAttempts is reached, the entry is no longer published
+}
+
+// publishEvent adds an event to the outbox, from where it is published by RunEventPublisher.
+// Events are only recorded if Kafka is configured. Errors are logged, they do not fail the operation causing the event.
+// The outbox entry is written after the change causing the event, so delivery is best-effort: an event is lost, if
+// the service stops or the database fails between both writes.
+func (r *Client) publishEvent(event lib.Event) {
+	if r.Publisher == nil {
+		return
+	}
+	event.Id = uuid.New().String()
+	event.Time = time.Now()
+	entry := outboxEntry{Id: event.Id, Topic: r.eventTopic(event.Type), Event: event, CreatedAt: event.Time}
+	if _, err := EventOutbox().InsertOne(CTX, entry); err != nil {
+		util.Logger.Error("could not record event "+event.Type+" of report "+event.ReportId, "error", err)
+	}
+}
+
+// validateKafkaConfig checks the intervals and limits of the event publisher, if Kafka is configured.
+func validateKafkaConfig(cfg *config.Config) error {
+	if cfg.Kafka.Bootstrap == "" {
+		return nil
+	}
+	if cfg.Kafka.OutboxInterval <= 0 {
+		return errors.New("outbox interval has to be positive")
+	}
+	if cfg.Kafka.OutboxBatch <= 0 {
+		return errors.New("outbox batch has to be positive")
+	}
+	if cfg.Kafka.OutboxAttempts <= 0 {
+		return errors.New("outbox attempts have to be positive")
+	}
+	if cfg.Kafka.Timeout <= 0 {
+		return errors.New("kafka timeout has to be positive")
+	}
+	return nil
+}
+
+func (r *Client) eventTopic(eventType string) string {
+	switch eventType {
+	case EventFileCreated:
+		return r.Config.Kafka.TopicFiles
+	case EventRunFailed:
+		return r.Config.Kafka.TopicRuns
+	default:
+		return r.Config.Kafka.TopicReports
+	}
+}
+
+// RunEventPublisher regularly publishes the events of the outbox to Kafka. Published events are removed from the outbox,
+// failed events are retried with the next interval, so events are published at least once.
+// The method blocks until the context is done and returns immediately, if Kafka is not configured.
+//
+// Parameters:
+// - ctx: Context to stop the publisher.
+//
+// Returns:
+// - err: The error of the context.
+func (r *Client) RunEventPublisher(ctx context.Context) error {
+	if r.Publisher == nil {
+		return nil
+	}
+	defer func() {
+		if err := r.Publisher.Close(); err != nil {
+			util.Logger.Error("could not close event publisher", "error", err)
+		}
+	}()
+	ticker := time.NewTicker(r.Config.Kafka.OutboxInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			util.Logger.Info("event publisher received shutdown signal")
+			return ctx.Err()
+		case <-ticker.C:
+			// publish full batches right away
+			for {
+				published, err := r.publishOutbox(ctx)
+				if err != nil {
+					util.Logger.Error("could not publish events", "error", err)
+				}
+				if err != nil || published < r.Config.Kafka.OutboxBatch {
+					break
+				}
+			}
+		}
+	}
+}
+
+// publishOutbox claims a batch of outbox entries, oldest first, and publishes them.
+// Claims expire, so entries of a stopped instance are published by another one.
+// If the batch can not be published, its entries are published one by one until one fails. The failed entry is retried
+// with increasing delay, so an entry which can not be published does not block the following ones.
+func (r *Client) publishOutbox(ctx context.Context) (published int, err error) {
+	now := time.Now()
+	lockedUntil := now.Add(3*r.Config.Kafka.Timeout + r.Config.Kafka.OutboxInterval)
+	var entries []outboxEntry
+	for len(entries) < r.Config.Kafka.OutboxBatch {
+		var entry outboxEntry
+		err = EventOutbox().FindOneAndUpdate(CTX,
+			bson.M{"failedat": nil, "$or": []bson.M{{"lockeduntil": nil}, {"lockeduntil": bson.M{"$lt": now}}}},
+			bson.M{"$set": bson.M{"lockeduntil": lockedUntil}},
+			options.FindOneAndUpdate().SetSort(bson.M{"createdat": 1}),
+		).Decode(&entry)
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			err = nil
+			break
+		}
+		if err != nil {
+			return
+		}
+		entries = append(entries, entry)
+	}
+	if len(entries) == 0 {
+		return
+	}
+	ids := make([]string, 0, len(entries))
+	messages := make([]kafka.Message, 0, len(entries))
+	for _, entry := range entries {
+		ids = append(ids, entry.Id)
+		value, marshalErr := json.Marshal(entry.Event)
+		if marshalErr != nil {
+			return 0, marshalErr
+		}
+		messages = append(messages, kafka.Message{Topic: entry.Topic, Key: entry.Event.ReportId, Value: value})
+	}
+	err = r.publishMessages(ctx, messages)
+	if err == nil {
+		_, err = EventOutbox().DeleteMany(CTX, bson.M{"_id": bson.M{"$in": ids}})
+		return len(entries), err
+	}
+	// find the entry, which can not be published
+	for i, entry := range entries {
+		publishErr := r.publishMessages(ctx, messages[i:i+1])
+		if publishErr == nil {
+			published++
+			continue
+		}
+		err = errors.Join(publishErr, r.retryOutboxEntry(entry, publishErr))
+		_, releaseErr := EventOutbox().UpdateMany(CTX, bson.M{"_id": bson.M{"$in": ids[i+1:]}}, bson.M{"$set": bson.M{"lockeduntil": nil}})
+		err = errors.Join(err, releaseErr)
+		break
+	}
+	if published > 0 {
+		_, deleteErr := EventOutbox().DeleteMany(CTX, bson.M{"_id": bson.M{"$in": ids[:published]}})
+		err = errors.Join(err, deleteErr)
+	}
+	return
+}
+
+func (r *Client) publishMessages(ctx context.Context, messages []kafka.Message) error {
+	publishCtx, cancel := context.WithTimeout(ctx, r.Config.Kafka.Timeout)
+	defer cancel()
+	return r.Publisher.Publish(publishCtx, messages)
+}
+
+// retryOutboxEntry records a failed attempt to publish an entry. The entry is locked until the next attempt, the delay
+// doubles with every attempt up to an hour. After Kafka.OutboxAttempts attempts the entry is marked as failed.
+func (r *Client) retryOutboxEntry(entry outboxEntry, publishErr error) error {
+	now := time.Now()
+	attempts := entry.Attempts + 1
+	set := bson.M{"lasterror": publishErr.Error()}
+	if attempts >= r.Config.Kafka.OutboxAttempts {
+		set["failedat"] = now
+		util.Logger.Error("giving up publishing event "+entry.Id+" of report "+entry.Event.ReportId, "error", publishErr)
+	} else {
+		set["lockeduntil"] = now.Add(min(r.Config.Kafka.OutboxInterval<<min(attempts, 20), time.Hour))
+	}
+	_, err := EventOutbox().UpdateOne(CTX, bson.M{"_id": entry.Id}, bson.M{"$set": set, "$inc": bson.M{"attempts": 1}})
+	return err
+}
+
+// reportEvent creates an event about a report.
+func reportEvent(eventType string, report lib.Report) lib.Event {
+	return lib.Event{Type: eventType, ReportId: report.Id, ReportName: report.Name, UserId: report.UserId, Version: report.Version}
+}
+
+// fileEvent creates an event about a report file.
+func fileEvent(eventType string, report lib.Report, file lib.ReportFile) lib.Event {
+	event := reportEvent(eventType, report)
+	event.Version = file.ReportVersion
+	event.FileId = file.Id
+	event.FileType = file.Type
+	event.RunId = file.RunId
+	return event
+}
@@ -22,6 +22,7 @@ import (
 	"time"
 
 	snrgyModels "github.com/SENERGY-Platform/models/go/models"
+	"github.com/SENERGY-Platform/reporting-service/lib"
 )
 
 func ParseDuration(s string) (time.Duration, error) {
@@ -94,3 +95,49 @@ func hasAttributeWithKey(attributes []snrgyModels.Attribute, key string) bool {
 	}
 	return false
 }
+
+// getDeviceQueryWindow resolves the time window of a device query relative to now.
+// Period takes precedence over Start, which takes precedence over Last.
+func getDeviceQueryWindow(query lib.DeviceQuery, now time.Time) (start time.Time, end time.Time, err error) {
+	end = now
+	if query.End != nil {
+		end, err = time.Parse(time.RFC3339, *query.End)
+		if err != nil {
+			return
+		}
+	}
+	switch {
+	case query.Period != nil:
+		now = now.UTC()
+		today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.UTC)
+		switch *query.Period {
+		case "lastDay":
+			end = today
+			start = end.AddDate(0, 0, -1)
+		case "lastWeek":
+			// weeks start on monday
+			end = today.AddDate(0, 0, -(int(today.Weekday())+6)%7)
+			start = end.AddDate(0, 0, -7)
+		case "lastMonth":
+			end = time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, time.UTC)
+			start = end.AddDate(0, -1, 0)
+		case "lastYear":
+			end = time.Date(now.Year(), 1, 1, 0, 0, 0, 0, time.UTC)
+			start = end.AddDate(-1, 0, 0)
+		default:
+			err = errors.New("unknown period " + *query.Period)
+		}
+	case query.Start != nil:
+		start, err = time.Parse(time.RFC3339, *query.Start)
+	case query.Last != nil:
+		var duration time.Duration
+		duration, err = ParseDuration(*query.Last)
+		start = end.Add(-duration)
+	default:
+		err = errors.New("device query requires one of last, start or period")
+	}
+	if err == nil && !start.Before(end) {
+		err = errors.New("device query start has to be before end")
+	}
+	return
+}
@@ -19,6 +19,8 @@ package report_engine
 import "github.com/SENERGY-Platform/reporting-service/lib"
 
 type ReportingDriver interface {
+	// Name returns the name of the driver, which is recorded in exported report bundles.
+	Name() string
 	GetTemplates(string) ([]lib.Template, error)
 	GetTemplateById(string, string) (lib.Template, error)
 	// CreateReport creates a report with the given ID and data.
@@ -0,0 +1,141 @@
+/*
+ * Copyright 2025 InfAI (CC SES)
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package report_engine
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/SENERGY-Platform/reporting-service/lib"
+	"github.com/globalsign/mgo/bson"
+	mongobson "go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+var ErrValidation = errors.New("invalid request")
+
+// reportSortFields maps the sortable fields of a report listing to their database fields.
+// Database field names are accepted as well for compatibility.
+var reportSortFields = map[string]string{
+	"id":           "_id",
+	"name":         "name",
+	"templateName": "templatename",
+	"templatename": "templatename",
+	"createdAt":    "createdat",
+	"createdat":    "createdat",
+	"updatedAt":    "updatedat",
+	"updatedat":    "updatedat",
+	"lastRun":      "lastrun.time",
+}
+
+const runStatusNever = "never"
+
+func reportListOptions(args map[string][]string) (opt *options.FindOptions, err error) {
+	opt = options.Find()
+	if val, ok := args["limit"]; ok {
+		limit, err := strconv.ParseInt(val[0], 10, 64)
+		if err != nil || limit < 0 {
+			return nil, fmt.Errorf("%w: invalid limit %q", ErrValidation, val[0])
+		}
+		opt.SetLimit(limit)
+	}
+	if val, ok := args["offset"]; ok {
+		skip, err := strconv.ParseInt(val[0], 10, 64)
+		if err != nil || skip < 0 {
+			return nil, fmt.Errorf("%w: invalid offset %q", ErrValidation, val[0])
+		}
+		opt.SetSkip(skip)
+	}
+	sortField, direction := "name", 1
+	if val, ok := args["order"]; ok {
+		field, dir, _ := strings.Cut(val[0], ":")
+		dbField, ok := reportSortFields[field]
+		if !ok {
+			return nil, fmt.Errorf("%w: invalid order field %q", ErrValidation, field)
+		}
+		sortField = dbField
+		switch dir {
+		case "", "asc":
+			direction = 1
+		case "desc":
+			direction = -1
+		default:
+			return nil, fmt.Errorf("%w: invalid order direction %q", ErrValidation, dir)
+		}
+	}
+	// the id makes the order stable across pages
+	opt.SetSort(mongobson.D{{Key: sortField, Value: direction}, {Key: "_id", Value: 1}})
+	return
+}
+
+func reportListFilters(args map[string][]string) (filters []bson.M, err error) {
+	if val, ok := args["search"]; ok && val[0] != "" {
+		pattern := bson.M{"$regex": regexp.QuoteMeta(val[0]), "$options": "i"}
+		filters = append(filters, bson.M{"$or": []bson.M{{"name": pattern}, {"templatename": pattern}, {"_id": pattern}}})
+	}
+	if val, ok := args["templateName"]; ok {
+		filters = append(filters, bson.M{"templatename": bson.M{"$in": val}})
+	}
+	if val, ok := args["hasCron"]; ok {
+		hasCron, err := strconv.ParseBool(val[0])
+		if err != nil {
+			return nil, fmt.Errorf("%w: invalid hasCron %q", ErrValidation, val[0])
+		}
+		noCron := []interface{}{"", nil}
+		if hasCron {
+			filters = append(filters, bson.M{"cron": bson.M{"$nin": noCron}})
+		} else {
+			filters = append(filters, bson.M{"cron": bson.M{"$in": noCron}})
+		}
+	}
+	for _, field := range []string{"created", "updated"} {
+		timeRange := bson.M{}
+		for arg, operator := range map[string]string{field + "Since": "$gte", field + "Until": "$lt"} {
+			val, ok := args[arg]
+			if !ok {
+				continue
+			}
+			t, err := time.Parse(time.RFC3339, val[0])
+			if err != nil {
+				return nil, fmt.Errorf("%w: invalid %s %q", ErrValidation, arg, val[0])
+			}
+			timeRange[operator] = t
+		}
+		if len(timeRange) > 0 {
+			filters = append(filters, bson.M{field + "at": timeRange})
+		}
+	}
+	if val, ok := args["lastRunStatus"]; ok {
+		var statusFilters []bson.M
+		for _, status := range val {
+			switch status {
+			case lib.RunStatusSuccess, lib.RunStatusFailed:
+				statusFilters = append(statusFilters, bson.M{"lastrun.status": status})
+			case runStatusNever:
+				statusFilters = append(statusFilters, bson.M{"lastrun": nil})
+			default:
+				return nil, fmt.Errorf("%w: invalid lastRunStatus %q", ErrValidation, status)
+			}
+		}
+		filters = append(filters, bson.M{"$or": statusFilters})
+	}
+	return
+}
@@ -0,0 +1,96 @@
+/*
+ * Copyright 2025 InfAI (CC SES)
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package report_engine
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"slices"
+	"strings"
+	"syscall"
+	"time"
+)
+
+// Webhooks and S3 targets are defined by users, so requests to them must not reach the network of the service.
+// Hosts resolving to loopback, private or link-local addresses are refused, unless allowed by DELIVERY_ALLOWED_HOSTS.
+
+// privateAddress reports if the address is not publicly routable.
+func privateAddress(ip net.IP) bool {
+	return ip.IsLoopback() || ip.IsPrivate() || ip.IsLinkLocalUnicast() || ip.IsLinkLocalMulticast() ||
+		ip.IsInterfaceLocalMulticast() || ip.IsMulticast() || ip.IsUnspecified()
+}
+
+// allowedHost reports if the host may be addressed regardless of its addresses.
+func (r *Client) allowedHost(host string) bool {
+	for _, allowed := range strings.Split(r.Config.Delivery.AllowedHosts, ",") {
+		if allowed = strings.TrimSpace(allowed); allowed != "" && strings.EqualFold(allowed, host) {
+			return true
+		}
+	}
+	return false
+}
+
+// validateOutboundUrl checks that a user defined url is an http(s) url of a public host. Name describes the url in errors.
+func (r *Client) validateOutboundUrl(name string, rawUrl string) error {
+	u, err := url.Parse(rawUrl)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Hostname() == "" {
+		return fmt.Errorf("%w: invalid %s %q", ErrValidation, name, rawUrl)
+	}
+	host := u.Hostname()
+	if r.allowedHost(host) {
+		return nil
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	addresses, err := net.DefaultResolver.LookupIPAddr(ctx, host)
+	if err != nil {
+		return fmt.Errorf("%w: could not resolve host %q of %s", ErrValidation, host, name)
+	}
+	if slices.ContainsFunc(addresses, func(address net.IPAddr) bool { return privateAddress(address.IP) }) {
+		return fmt.Errorf("%w: host %q of %s resolves to a private address", ErrValidation, host, name)
+	}
+	return nil
+}
+
+// outboundTransport creates a transport for requests to user defined urls. Connections to private addresses are refused
+// when they are established, so hosts resolving differently than at validation are refused as well.
+func (r *Client) outboundTransport() *http.Transport {
+	dialer := &net.Dialer{Timeout: 30 * time.Second, KeepAlive: 30 * time.Second}
+	publicDialer := *dialer
+	publicDialer.Control = func(network string, address string, _ syscall.RawConn) error {
+		host, _, err := net.SplitHostPort(address)
+		if err != nil {
+			return err
+		}
+		if ip := net.ParseIP(host); ip == nil || privateAddress(ip) {
+			return fmt.Errorf("connection to private address %s refused", address)
+		}
+		return nil
+	}
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.DialContext = func(ctx context.Context, network string, address string) (net.Conn, error) {
+		host, _, err := net.SplitHostPort(address)
+		if err == nil && r.allowedHost(host) {
+			return dialer.DialContext(ctx, network, address)
+		}
+		return publicDialer.DialContext(ctx, network, address)
+	}
+	return transport
+}
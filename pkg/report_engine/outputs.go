@@ -0,0 +1,78 @@
+/*
+ * Copyright 2025 InfAI (CC SES)
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package report_engine
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/SENERGY-Platform/reporting-service/lib"
+	"github.com/SENERGY-Platform/reporting-service/pkg/util"
+)
+
+// prepareOutputs validates the additional outputs of a report.
+func prepareOutputs(outputs []lib.ReportOutput) error {
+	for _, output := range outputs {
+		if output.TemplateName == "" {
+			return fmt.Errorf("%w: output without template name", ErrValidation)
+		}
+	}
+	return nil
+}
+
+// createOutputFiles renders the resolved data with the additional outputs of the report.
+// The created files share the run values of the main file. If an output fails, the files created so far are deleted.
+func (r *Client) createOutputFiles(report lib.Report, data map[string]interface{}, mainFile lib.ReportFile, authTokenString string) (files []lib.ReportFile, err error) {
+	for _, output := range report.Outputs {
+		file := mainFile
+		file.TemplateName = output.TemplateName
+		file.Id, file.Type, file.Link, err = r.Driver.CreateReport(report.Name, output.TemplateName, data, authTokenString)
+		file.CreatedAt = time.Now()
+		files = append(files, file)
+		if err != nil {
+			r.deleteDriverFiles(files, authTokenString)
+			return nil, fmt.Errorf("could not create output %s: %w", output.TemplateName, err)
+		}
+	}
+	return
+}
+
+// deleteDriverFiles removes created files, which are not recorded in a report, from the driver.
+func (r *Client) deleteDriverFiles(files []lib.ReportFile, authTokenString string) {
+	for _, file := range files {
+		if file.Id == "" {
+			continue
+		}
+		if err := r.Driver.DeleteCreatedReportFile(file.Id, authTokenString); err != nil {
+			util.Logger.Error("could not delete report file "+file.Id, "error", err)
+		}
+	}
+}
+
+// runFiles returns the files created by the run of the given file, starting with the file itself.
+func runFiles(report lib.Report, file lib.ReportFile) []lib.ReportFile {
+	files := []lib.ReportFile{file}
+	if file.RunId == "" {
+		return files
+	}
+	for _, other := range report.ReportFiles {
+		if other.RunId == file.RunId && other.Id != file.Id {
+			files = append(files, other)
+		}
+	}
+	return files
+}
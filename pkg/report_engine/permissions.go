@@ -0,0 +1,128 @@
+/*
+ * Copyright 2025 InfAI (CC SES)
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package report_engine
+
+import (
+	"errors"
+	"slices"
+
+	"github.com/SENERGY-Platform/reporting-service/lib"
+	"github.com/SENERGY-Platform/service-commons/pkg/jwt"
+	"github.com/globalsign/mgo/bson"
+)
+
+var ErrForbidden = errors.New("forbidden")
+
+type Permission string
+
+const (
+	PermissionRead         Permission = "read"
+	PermissionExecute      Permission = "execute"
+	PermissionAdministrate Permission = "administrate"
+)
+
+// grantFields returns the grant fields, which each allow the given permission.
+func (p Permission) grantFields() []string {
+	switch p {
+	case PermissionRead:
+		return []string{"read", "execute", "administrate"}
+	case PermissionExecute:
+		return []string{"execute", "administrate"}
+	default:
+		return []string{"administrate"}
+	}
+}
+
+func (p Permission) granted(grant lib.PermissionGrant) bool {
+	switch p {
+	case PermissionRead:
+		return grant.Read || grant.Execute || grant.Administrate
+	case PermissionExecute:
+		return grant.Execute || grant.Administrate
+	default:
+		return grant.Administrate
+	}
+}
+
+// permissionFilter returns a MongoDB filter matching all reports the user owns or has been granted the permission for.
+func permissionFilter(claims jwt.Token, permission Permission) bson.M {
+	var fieldFilters []bson.M
+	for _, field := range permission.grantFields() {
+		fieldFilters = append(fieldFilters, bson.M{field: true})
+	}
+	or := []bson.M{
+		{"userid": claims.GetUserId()},
+		{"permissions.users": bson.M{"$elemMatch": bson.M{"id": claims.GetUserId(), "$or": fieldFilters}}},
+	}
+	if len(claims.GetGroups()) > 0 {
+		or = append(or, bson.M{"permissions.groups": bson.M{"$elemMatch": bson.M{"id": bson.M{"$in": claims.GetGroups()}, "$or": fieldFilters}}})
+	}
+	return bson.M{"$or": or}
+}
+
+// hasPermission checks if the user owns the report or has been granted the permission.
+func hasPermission(report lib.Report, claims jwt.Token, permission Permission) bool {
+	if report.UserId == claims.GetUserId() {
+		return true
+	}
+	for _, grant := range report.Permissions.Users {
+		if grant.Id == claims.GetUserId() && permission.granted(grant) {
+			return true
+		}
+	}
+	for _, grant := range report.Permissions.Groups {
+		if slices.Contains(claims.GetGroups(), grant.Id) && permission.granted(grant) {
+			return true
+		}
+	}
+	return false
+}
+
+func validatePermissions(permissions lib.Permissions) error {
+	for _, grant := range append(permissions.Users, permissions.Groups...) {
+		if grant.Id == "" {
+			return errors.New("permission grant without id")
+		}
+	}
+	return nil
+}
+
+// SetReportPermissions replaces the permissions of a report. Requires the administrate permission.
+//
+// Parameters:
+// - id: The ID of the report.
+// - permissions: The new permissions.
+// - authTokenString: The authentication token string.
+//
+// Returns:
+// - err: An error if the operation fails.
+func (r *Client) SetReportPermissions(id string, permissions lib.Permissions, authTokenString string) (err error) {
+	claims, err := jwt.Parse(authTokenString)
+	if err != nil {
+		return
+	}
+	err = validatePermissions(permissions)
+	if err != nil {
+		return
+	}
+	_, err = r.getReportModel(id, claims, PermissionAdministrate)
+	if err != nil {
+		return
+	}
+	_, err = Reports().UpdateOne(CTX, bson.M{"_id": id}, bson.M{"$set": bson.M{"permissions": permissions}})
+	return
+}
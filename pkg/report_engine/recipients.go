@@ -0,0 +1,115 @@
+/*
+ * Copyright 2025 InfAI (CC SES)
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package report_engine
+
+import (
+	"fmt"
+	"net/mail"
+
+	"github.com/SENERGY-Platform/reporting-service/lib"
+	"github.com/SENERGY-Platform/reporting-service/pkg/apis/mailer"
+)
+
+// prepareRecipients validates all email addresses of a report and sets the default recipient role.
+func prepareRecipients(report *lib.Report) error {
+	for _, receiver := range report.EmailReceivers {
+		if err := validateEmail(receiver); err != nil {
+			return err
+		}
+	}
+	if err := prepareRecipientList(report.Recipients); err != nil {
+		return err
+	}
+	if report.ReplyTo != nil {
+		return validateEmail(report.ReplyTo.Email)
+	}
+	return nil
+}
+
+func prepareRecipientList(recipients []lib.Recipient) error {
+	for i := range recipients {
+		recipient := &recipients[i]
+		if err := validateEmail(recipient.Email); err != nil {
+			return err
+		}
+		switch recipient.Role {
+		case "":
+			recipient.Role = lib.RecipientRoleTo
+		case lib.RecipientRoleTo, lib.RecipientRoleCc, lib.RecipientRoleBcc:
+		default:
+			return fmt.Errorf("%w: invalid recipient role %q", ErrValidation, recipient.Role)
+		}
+	}
+	return nil
+}
+
+func validateEmail(email string) error {
+	address, err := mail.ParseAddress(email)
+	if err != nil || address.Address != email {
+		return fmt.Errorf("%w: invalid email address %q", ErrValidation, email)
+	}
+	return nil
+}
+
+// hasRecipients checks if a report is emailed.
+func hasRecipients(report lib.Report) bool {
+	return len(report.EmailReceivers) > 0 || len(report.Recipients) > 0
+}
+
+// recipientCount returns the number of email recipients of a report.
+func recipientCount(report lib.Report) int {
+	return len(report.EmailReceivers) + len(report.Recipients)
+}
+
+// splitRecipients distributes the recipients of a report to messages with at most limit recipients each.
+// Recipients keep their role, but only see the recipients of the same message.
+func splitRecipients(report lib.Report, base mailer.Message, limit int) (messages []mailer.Message) {
+	type entry struct {
+		role    string
+		address mailer.Address
+	}
+	var entries []entry
+	for _, role := range []string{lib.RecipientRoleTo, lib.RecipientRoleCc, lib.RecipientRoleBcc} {
+		for _, recipient := range report.Recipients {
+			if recipient.Role == role || (recipient.Role == "" && role == lib.RecipientRoleTo) {
+				entries = append(entries, entry{role: role, address: mailer.Address{Name: recipient.Name, Email: recipient.Email}})
+			}
+		}
+	}
+	for _, receiver := range report.EmailReceivers {
+		entries = append(entries, entry{role: lib.RecipientRoleBcc, address: mailer.Address{Email: receiver}})
+	}
+	if limit <= 0 {
+		limit = len(entries)
+	}
+	for start := 0; start < len(entries); start += limit {
+		message := base
+		message.To, message.Cc, message.Bcc = nil, nil, nil
+		for _, e := range entries[start:min(start+limit, len(entries))] {
+			switch e.role {
+			case lib.RecipientRoleTo:
+				message.To = append(message.To, e.address)
+			case lib.RecipientRoleCc:
+				message.Cc = append(message.Cc, e.address)
+			default:
+				message.Bcc = append(message.Bcc, e.address.Email)
+			}
+		}
+		messages = append(messages, message)
+	}
+	return
+}
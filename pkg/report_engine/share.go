@@ -0,0 +1,209 @@
+/*
+ * Copyright 2025 InfAI (CC SES)
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package report_engine
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"net/url"
+	"slices"
+	"strconv"
+	"time"
+
+	"github.com/SENERGY-Platform/reporting-service/lib"
+	"github.com/SENERGY-Platform/reporting-service/pkg/util"
+	"github.com/SENERGY-Platform/service-commons/pkg/jwt"
+	"github.com/globalsign/mgo/bson"
+	"github.com/google/uuid"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+var ErrLinkExpired = errors.New("link expired or revoked")
+
+// ShareReportFile creates a signed, expiring public download link for a report file. Requires the administrate permission.
+//
+// Parameters:
+// - reportId: The ID of the report.
+// - fileId: The ID of the file to share.
+// - request: The share options.
+// - authTokenString: The authentication token string.
+//
+// Returns:
+// - share: The created share including its url.
+// - err: An error if the operation fails.
+func (r *Client) ShareReportFile(reportId string, fileId string, request lib.FileShareRequest, authTokenString string) (share lib.FileShare, err error) {
+	claims, err := jwt.Parse(authTokenString)
+	if err != nil {
+		return
+	}
+	if r.Config.Share.Secret.Value() == "" {
+		return share, errors.New("file sharing is not configured")
+	}
+	report, err := r.getReportModel(reportId, claims, PermissionAdministrate)
+	if err != nil {
+		return
+	}
+	if !slices.ContainsFunc(report.ReportFiles, func(file lib.ReportFile) bool { return file.Id == fileId }) {
+		return share, mongo.ErrNoDocuments
+	}
+	ttl := r.Config.Share.DefaultTTL
+	if request.ExpiresIn != "" {
+		ttl, err = ParseDuration(request.ExpiresIn)
+		if err != nil {
+			return
+		}
+	}
+	if ttl <= 0 || (r.Config.Share.MaxTTL > 0 && ttl > r.Config.Share.MaxTTL) {
+		return share, errors.New("invalid link lifetime " + ttl.String())
+	}
+	return r.createFileShare(report, fileId, ttl)
+}
+
+// createFileShare creates a link to a file of the report without permission checks.
+func (r *Client) createFileShare(report lib.Report, fileId string, ttl time.Duration) (share lib.FileShare, err error) {
+	if r.Config.Share.Secret.Value() == "" {
+		return share, errors.New("file sharing is not configured")
+	}
+	now := time.Now()
+	share = lib.FileShare{
+		Id:        uuid.New().String(),
+		ReportId:  report.Id,
+		FileId:    fileId,
+		UserId:    report.UserId, // downloads are executed on behalf of the owner
+		ExpiresAt: now.Add(ttl),
+		CreatedAt: now,
+	}
+	_, err = FileShares().InsertOne(CTX, share)
+	if err != nil {
+		return
+	}
+	share.Url = r.shareUrl(share)
+	return
+}
+
+// GetReportFileShares lists all links of a report file. Requires the administrate permission.
+func (r *Client) GetReportFileShares(reportId string, fileId string, authTokenString string) (shares []lib.FileShare, err error) {
+	claims, err := jwt.Parse(authTokenString)
+	if err != nil {
+		return
+	}
+	_, err = r.getReportModel(reportId, claims, PermissionAdministrate)
+	if err != nil {
+		return
+	}
+	cur, err := FileShares().Find(CTX, bson.M{"reportid": reportId, "fileid": fileId}, options.Find().SetSort(bson.M{"createdat": -1}))
+	if err != nil {
+		return
+	}
+	shares = []lib.FileShare{}
+	err = cur.All(CTX, &shares)
+	return
+}
+
+// RevokeReportFileShare revokes a link of a report file. Requires the administrate permission.
+func (r *Client) RevokeReportFileShare(reportId string, fileId string, shareId string, authTokenString string) (err error) {
+	claims, err := jwt.Parse(authTokenString)
+	if err != nil {
+		return
+	}
+	_, err = r.getReportModel(reportId, claims, PermissionAdministrate)
+	if err != nil {
+		return
+	}
+	res, err := FileShares().UpdateOne(CTX, bson.M{"_id": shareId, "reportid": reportId, "fileid": fileId}, bson.M{"$set": bson.M{"revoked": true}})
+	if err != nil {
+		return
+	}
+	if res.MatchedCount == 0 {
+		return mongo.ErrNoDocuments
+	}
+	return
+}
+
+// DownloadSharedReportFile downloads a report file by a public link without user authentication.
+//
+// Parameters:
+// - shareId: The ID of the link.
+// - expires: The expiration timestamp of the link in unix seconds.
+// - signature: The signature of the link.
+//
+// Returns:
+// - share: The link.
+// - content: The content of the file.
+// - contentType: The content type of the file.
+// - fileTypeExtension: The file type extension of the report.
+// - err: ErrForbidden if the signature is invalid, ErrLinkExpired if the link has expired or was revoked.
+func (r *Client) DownloadSharedReportFile(shareId string, expires string, signature string) (share lib.FileShare, content []byte, contentType string, fileTypeExtension string, err error) {
+	if r.Config.Share.Secret.Value() == "" || !hmac.Equal([]byte(signature), []byte(r.signShare(shareId, expires))) {
+		err = ErrForbidden
+		return
+	}
+	expiresAt, err := strconv.ParseInt(expires, 10, 64)
+	if err != nil {
+		err = ErrForbidden
+		return
+	}
+	now := time.Now()
+	if now.Unix() > expiresAt {
+		err = ErrLinkExpired
+		return
+	}
+	err = FileShares().FindOne(CTX, bson.M{"_id": shareId, "revoked": false, "expiresat": bson.M{"$gt": now}}).Decode(&share)
+	if errors.Is(err, mongo.ErrNoDocuments) {
+		err = ErrLinkExpired
+		return
+	}
+	if err != nil {
+		return
+	}
+	token, _, err := jwt.ExchangeUserToken(
+		r.Config.Keycloak.Url,
+		r.Config.Keycloak.ClientId,
+		r.Config.Keycloak.ClientSecret,
+		share.UserId,
+	)
+	if err != nil {
+		return
+	}
+	content, contentType, fileTypeExtension, err = r.DownloadReportFile(share.ReportId, share.FileId, token.Token)
+	if err != nil {
+		return
+	}
+	// only successful downloads are counted, the file is returned even if counting fails
+	_, countErr := FileShares().UpdateOne(CTX, bson.M{"_id": shareId}, bson.M{"$inc": bson.M{"downloads": 1}, "$set": bson.M{"lastdownloadat": time.Now()}})
+	if countErr != nil {
+		util.Logger.Error("could not count download of share "+shareId, "error", countErr)
+	}
+	return
+}
+
+func (r *Client) shareUrl(share lib.FileShare) string {
+	expires := strconv.FormatInt(share.ExpiresAt.Unix(), 10)
+	query := url.Values{}
+	query.Set("expires", expires)
+	query.Set("signature", r.signShare(share.Id, expires))
+	return r.Config.Share.BaseUrl + "/shared/file/" + share.Id + "?" + query.Encode()
+}
+
+func (r *Client) signShare(shareId string, expires string) string {
+	mac := hmac.New(sha256.New, []byte(r.Config.Share.Secret.Value()))
+	mac.Write([]byte(shareId + ":" + expires))
+	return hex.EncodeToString(mac.Sum(nil))
+}
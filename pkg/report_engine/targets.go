@@ -0,0 +1,187 @@
+/*
+ * Copyright 2025 InfAI (CC SES)
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package report_engine
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/SENERGY-Platform/reporting-service/lib"
+	"github.com/SENERGY-Platform/reporting-service/pkg/apis/s3"
+	"github.com/google/uuid"
+)
+
+var keyPlaceholder = regexp.MustCompile(`\{(\w+)\}`)
+
+var keyPlaceholders = []string{"report", "reportName", "fileId", "ext", "yyyy", "mm", "dd", "HH", "MM"}
+
+func (r *Client) prepareTargets(targets []lib.DeliveryTarget, old []lib.DeliveryTarget) error {
+	for i := range targets {
+		target := &targets[i]
+		if target.Id == "" {
+			target.Id = uuid.New().String()
+		}
+		if target.KeyTemplate == "" {
+			target.KeyTemplate = lib.DefaultKeyTemplate
+		}
+		for _, match := range keyPlaceholder.FindAllStringSubmatch(target.KeyTemplate, -1) {
+			if !slices.Contains(keyPlaceholders, match[1]) {
+				return fmt.Errorf("%w: unknown placeholder %s in key template", ErrValidation, match[0])
+			}
+		}
+		switch target.Type {
+		case lib.TargetTypeS3:
+			if target.S3 == nil || target.S3.Bucket == "" || target.S3.AccessKey == "" {
+				return fmt.Errorf("%w: s3 target requires bucket and access key", ErrValidation)
+			}
+			if target.S3.Region == "" {
+				target.S3.Region = "us-east-1"
+			}
+			if target.S3.Endpoint == "" {
+				target.S3.Endpoint = "https://s3." + target.S3.Region + ".amazonaws.com"
+			}
+			if err := r.validateOutboundUrl("s3 endpoint", target.S3.Endpoint); err != nil {
+				return err
+			}
+			if target.S3.SecretKey == "" {
+				index := slices.IndexFunc(old, func(o lib.DeliveryTarget) bool { return o.Id == target.Id && o.S3 != nil })
+				if index < 0 {
+					return fmt.Errorf("%w: s3 target requires secret key", ErrValidation)
+				}
+				target.S3.SecretKey = old[index].S3.SecretKey
+			}
+		case lib.TargetTypeFilesystem:
+			if r.Config.Delivery.FilesystemRoot == "" {
+				return fmt.Errorf("%w: filesystem targets are not enabled", ErrValidation)
+			}
+			if target.Filesystem == nil {
+				target.Filesystem = &lib.FilesystemTarget{}
+			}
+			if _, err := r.targetDirectory(*target); err != nil {
+				return err
+			}
+		default:
+			return fmt.Errorf("%w: invalid target type %q", ErrValidation, target.Type)
+		}
+	}
+	return nil
+}
+
+// renderKey replaces the placeholders of a key template.
+func renderKey(template string, report lib.Report, fileId string, ext string, createdAt time.Time) string {
+	values := map[string]string{
+		"report":     report.Id,
+		"reportName": sanitizeKeySegment(report.Name),
+		"fileId":     fileId,
+		"ext":        ext,
+		"yyyy":       createdAt.Format("2006"),
+		"mm":         createdAt.Format("01"),
+		"dd":         createdAt.Format("02"),
+		"HH":         createdAt.Format("15"),
+		"MM":         createdAt.Format("04"),
+	}
+	return keyPlaceholder.ReplaceAllStringFunc(template, func(match string) string {
+		return values[match[1:len(match)-1]]
+	})
+}
+
+func sanitizeKeySegment(value string) string {
+	return strings.Map(func(r rune) rune {
+		if r == '/' || r == '\\' || r < 32 {
+			return '_'
+		}
+		return r
+	}, value)
+}
+
+// deliverToTarget stores a report file in a target and returns its location.
+func (r *Client) deliverToTarget(target lib.DeliveryTarget, report lib.Report, fileId string, content []byte, contentType string, ext string, createdAt time.Time) (location string, err error) {
+	key := strings.TrimPrefix(renderKey(target.KeyTemplate, report, fileId, ext, createdAt), "/")
+	ctx, cancel := context.WithTimeout(context.Background(), r.Config.Delivery.Timeout)
+	defer cancel()
+	switch target.Type {
+	case lib.TargetTypeS3:
+		client := s3.NewClient(target.S3.Endpoint, target.S3.Region, target.S3.Bucket, target.S3.AccessKey, target.S3.SecretKey, target.S3.PathStyle, r.Config.Delivery.Timeout)
+		client.HttpClient.Transport = r.outboundTransport()
+		location = "s3://" + target.S3.Bucket + "/" + key
+		err = client.PutObject(ctx, key, content, contentType)
+		return
+	case lib.TargetTypeFilesystem:
+		var dir string
+		dir, err = r.targetDirectory(target)
+		if err != nil {
+			return
+		}
+		location = filepath.Join(dir, filepath.FromSlash(key))
+		if !isWithin(dir, location) {
+			return location, errors.New("key leaves the target directory")
+		}
+		err = writeFileAtomic(location, content)
+		return
+	default:
+		return "", fmt.Errorf("invalid target type %q", target.Type)
+	}
+}
+
+// targetDirectory returns the directory of a filesystem target, which has to be inside the configured root.
+func (r *Client) targetDirectory(target lib.DeliveryTarget) (dir string, err error) {
+	root := filepath.Clean(r.Config.Delivery.FilesystemRoot)
+	if root == "" || root == "." {
+		return "", fmt.Errorf("%w: filesystem targets are not enabled", ErrValidation)
+	}
+	dir = filepath.Join(root, filepath.FromSlash(target.Filesystem.Path))
+	if !isWithin(root, dir) {
+		return "", fmt.Errorf("%w: target path %q leaves the delivery root", ErrValidation, target.Filesystem.Path)
+	}
+	return
+}
+
+func isWithin(root string, path string) bool {
+	rel, err := filepath.Rel(root, path)
+	return err == nil && rel != ".." && !strings.HasPrefix(rel, ".."+string(filepath.Separator))
+}
+
+// writeFileAtomic writes to a temporary file first, so watchers of the directory never see partial files.
+func writeFileAtomic(path string, content []byte) (err error) {
+	err = os.MkdirAll(filepath.Dir(path), 0o755)
+	if err != nil {
+		return
+	}
+	tmp, err := os.CreateTemp(filepath.Dir(path), ".tmp-*")
+	if err != nil {
+		return
+	}
+	defer os.Remove(tmp.Name())
+	if _, err = tmp.Write(content); err != nil {
+		_ = tmp.Close()
+		return
+	}
+	if err = tmp.Close(); err != nil {
+		return
+	}
+	if err = os.Chmod(tmp.Name(), 0o644); err != nil {
+		return
+	}
+	return os.Rename(tmp.Name(), path)
+}
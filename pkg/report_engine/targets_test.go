@@ -0,0 +1,55 @@
+/*
+ * Copyright 2025 InfAI (CC SES)
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package report_engine
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/SENERGY-Platform/reporting-service/lib"
+)
+
+func TestRenderKey(t *testing.T) {
+	report := lib.Report{Id: "report-1", Name: "Energy/Monthly"}
+	createdAt := time.Date(2025, 3, 7, 9, 5, 0, 0, time.UTC)
+	for template, want := range map[string]string{
+		lib.DefaultKeyTemplate:                         "report-1/2025/03/file-1.pdf",
+		"{reportName}/{yyyy}-{mm}-{dd}_{HH}{MM}.{ext}": "Energy_Monthly/2025-03-07_0905.pdf",
+		"static/name.pdf":                              "static/name.pdf",
+	} {
+		if got := renderKey(template, report, "file-1", "pdf", createdAt); got != want {
+			t.Errorf("renderKey(%q) = %q, want %q", template, got, want)
+		}
+	}
+}
+
+func TestPrepareTargetsKeyTemplate(t *testing.T) {
+	r := &Client{}
+	targets := []lib.DeliveryTarget{{Type: "unknown"}}
+	err := r.prepareTargets(targets, nil)
+	if targets[0].KeyTemplate != lib.DefaultKeyTemplate {
+		t.Errorf("key template = %q, want default", targets[0].KeyTemplate)
+	}
+	if !errors.Is(err, ErrValidation) {
+		t.Errorf("unknown target type: expected validation error, got %v", err)
+	}
+	err = r.prepareTargets([]lib.DeliveryTarget{{Type: lib.TargetTypeS3, KeyTemplate: "{report}/{year}.{ext}"}}, nil)
+	if !errors.Is(err, ErrValidation) {
+		t.Errorf("unknown placeholder: expected validation error, got %v", err)
+	}
+}
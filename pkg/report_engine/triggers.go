@@ -0,0 +1,365 @@
+/*
+ * Copyright 2025 InfAI (CC SES)
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package report_engine
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/SENERGY-Platform/reporting-service/lib"
+	"github.com/SENERGY-Platform/reporting-service/pkg/apis/device_manager"
+	"github.com/SENERGY-Platform/reporting-service/pkg/apis/kafka"
+	"github.com/SENERGY-Platform/reporting-service/pkg/config"
+	"github.com/SENERGY-Platform/reporting-service/pkg/util"
+	"github.com/SENERGY-Platform/service-commons/pkg/jwt"
+	"github.com/globalsign/mgo/bson"
+	"github.com/google/uuid"
+)
+
+// deviceLogMessage is a message of the device log topic, which is published when devices connect or disconnect.
+type deviceLogMessage struct {
+	Id        string `json:"id"`
+	Connected *bool  `json:"connected"`
+}
+
+// prepareTriggers validates the triggers and the debounce interval of a report and generates missing trigger IDs.
+// Devices of device offline triggers, which are not used by the old report, have to be readable by the report owner.
+func (r *Client) prepareTriggers(report *lib.Report, old lib.Report) error {
+	if report.Debounce != "" {
+		if _, err := time.ParseDuration(report.Debounce); err != nil {
+			return fmt.Errorf("%w: invalid debounce %q", ErrValidation, report.Debounce)
+		}
+	}
+	if len(report.Triggers) > 0 && r.Config.Kafka.Bootstrap == "" {
+		return fmt.Errorf("%w: triggers require kafka to be configured", ErrValidation)
+	}
+	for i := range report.Triggers {
+		trigger := &report.Triggers[i]
+		switch trigger.Type {
+		case lib.TriggerTypeKafka:
+			if trigger.Topic == "" {
+				return fmt.Errorf("%w: kafka trigger without topic", ErrValidation)
+			}
+			if !slices.Contains(r.triggerTopicsAllowed(), trigger.Topic) {
+				return fmt.Errorf("%w: topic %q is not allowed for triggers", ErrValidation, trigger.Topic)
+			}
+		case lib.TriggerTypeDeviceOffline:
+			trigger.Topic = ""
+			// the device log contains the devices of all users
+			if len(trigger.DeviceIds) == 0 {
+				return fmt.Errorf("%w: device offline trigger without devices", ErrValidation)
+			}
+		default:
+			return fmt.Errorf("%w: invalid trigger type %q", ErrValidation, trigger.Type)
+		}
+		for _, rule := range trigger.Filter {
+			if err := validateConditionRule(rule); err != nil {
+				return err
+			}
+		}
+		if trigger.Id == "" {
+			trigger.Id = uuid.New().String()
+		}
+	}
+	return r.checkTriggerDevices(*report, old)
+}
+
+// checkTriggerDevices verifies, that the owner of the report may read the devices of its triggers.
+// Devices used by the triggers of the old report have been checked before.
+func (r *Client) checkTriggerDevices(report lib.Report, old lib.Report) error {
+	checked := map[string]bool{}
+	for _, trigger := range old.Triggers {
+		for _, deviceId := range trigger.DeviceIds {
+			checked[deviceId] = true
+		}
+	}
+	var deviceIds []string
+	for _, trigger := range report.Triggers {
+		for _, deviceId := range trigger.DeviceIds {
+			if !checked[deviceId] {
+				checked[deviceId] = true
+				deviceIds = append(deviceIds, deviceId)
+			}
+		}
+	}
+	if len(deviceIds) == 0 {
+		return nil
+	}
+	token, _, err := jwt.ExchangeUserToken(
+		r.Config.Keycloak.Url,
+		r.Config.Keycloak.ClientId,
+		r.Config.Keycloak.ClientSecret,
+		report.UserId,
+	)
+	if err != nil {
+		return fmt.Errorf("could not exchange user token: %w", err)
+	}
+	for _, deviceId := range deviceIds {
+		_, err = r.DeviceManager.Get(context.Background(), token.Token, deviceId)
+		if errors.Is(err, device_manager.ErrNotFound) {
+			return fmt.Errorf("%w: unknown device %q in trigger", ErrValidation, deviceId)
+		}
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// triggerTopicsAllowed returns the topics, which kafka triggers may consume.
+// Triggers share the consumer group and credentials of the service, so users may not consume arbitrary topics.
+func (r *Client) triggerTopicsAllowed() (topics []string) {
+	for _, topic := range strings.Split(r.Config.Trigger.Topics, ",") {
+		if topic = strings.TrimSpace(topic); topic != "" {
+			topics = append(topics, topic)
+		}
+	}
+	return
+}
+
+// validateTriggerConfig checks the trigger config, if Kafka is configured.
+func validateTriggerConfig(cfg *config.Config) error {
+	if cfg.Kafka.Bootstrap == "" {
+		return nil
+	}
+	if cfg.Trigger.Refresh <= 0 {
+		return errors.New("trigger refresh has to be positive")
+	}
+	if cfg.Trigger.GroupId == "" {
+		return errors.New("missing trigger group id")
+	}
+	return nil
+}
+
+// RunTriggers consumes the topics used by report triggers and runs the reports with matching triggers.
+// The consumed topics are updated regularly, so new triggers are picked up without restart.
+// The method blocks until the context is done and returns immediately, if Kafka is not configured.
+//
+// Parameters:
+// - ctx: Context to stop the consumers.
+//
+// Returns:
+// - err: The error of the context.
+func (r *Client) RunTriggers(ctx context.Context) error {
+	if r.Config.Kafka.Bootstrap == "" {
+		return nil
+	}
+	runs := make(chan struct{}, max(r.Config.Trigger.Concurrency, 1))
+	var wg sync.WaitGroup
+	consumers := map[string]context.CancelFunc{}
+	defer func() {
+		for _, cancel := range consumers {
+			cancel()
+		}
+		wg.Wait()
+	}()
+	refresh := func() {
+		topics, err := r.triggerTopics()
+		if err != nil {
+			util.Logger.Error("could not get trigger topics", "error", err)
+			return
+		}
+		for topic, cancel := range consumers {
+			if !slices.Contains(topics, topic) {
+				util.Logger.Info("stopping trigger consumer for topic " + topic)
+				cancel()
+				delete(consumers, topic)
+			}
+		}
+		for _, topic := range topics {
+			if _, ok := consumers[topic]; ok {
+				continue
+			}
+			util.Logger.Info("starting trigger consumer for topic " + topic)
+			consumerCtx, cancel := context.WithCancel(ctx)
+			consumers[topic] = cancel
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				r.consumeTriggerTopic(consumerCtx, topic, runs, &wg)
+			}()
+		}
+	}
+	refresh()
+	ticker := time.NewTicker(r.Config.Trigger.Refresh)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			util.Logger.Info("trigger consumers received shutdown signal")
+			return ctx.Err()
+		case <-ticker.C:
+			refresh()
+		}
+	}
+}
+
+// triggerTopics returns the topics, which have to be consumed for the triggers of active reports.
+func (r *Client) triggerTopics() (topics []string, err error) {
+	active := bson.M{"paused": bson.M{"$ne": true}}
+	values, err := Reports().Distinct(CTX, "triggers.topic", active)
+	if err != nil {
+		return
+	}
+	allowed := r.triggerTopicsAllowed()
+	for _, value := range values {
+		// the allowed topics may have been reduced since the triggers were saved
+		if topic, ok := value.(string); ok && slices.Contains(allowed, topic) {
+			topics = append(topics, topic)
+		}
+	}
+	offline, err := Reports().CountDocuments(CTX, bson.M{"paused": bson.M{"$ne": true}, "triggers.type": lib.TriggerTypeDeviceOffline})
+	if err != nil {
+		return
+	}
+	if offline > 0 && !slices.Contains(topics, r.Config.Trigger.DeviceLogTopic) {
+		topics = append(topics, r.Config.Trigger.DeviceLogTopic)
+	}
+	return
+}
+
+// consumeTriggerTopic reads the messages of a topic until the context is done. Read errors are retried with increasing delay.
+// Every topic has its own consumer group, so starting and stopping consumers does not rebalance the other topics.
+func (r *Client) consumeTriggerTopic(ctx context.Context, topic string, runs chan struct{}, wg *sync.WaitGroup) {
+	consumer := kafka.NewConsumer(r.Config.Kafka.Bootstrap, r.Config.Trigger.GroupId+"-"+topic, topic)
+	defer func() {
+		if err := consumer.Close(); err != nil {
+			util.Logger.Error("could not close trigger consumer for topic "+topic, "error", err)
+		}
+	}()
+	backoff := time.Second
+	for {
+		message, err := consumer.Read(ctx)
+		if ctx.Err() != nil {
+			return
+		}
+		if err != nil {
+			util.Logger.Error("could not read trigger topic "+topic, "error", err)
+			select {
+			case <-ctx.Done():
+				return
+			case <-time.After(backoff):
+			}
+			backoff = min(2*backoff, time.Minute)
+			continue
+		}
+		backoff = time.Second
+		r.handleTriggerMessage(ctx, message, runs, wg)
+	}
+}
+
+// handleTriggerMessage runs the active reports with a trigger matching the message.
+// At most Trigger.Concurrency reports run at the same time, further messages wait for a free slot.
+func (r *Client) handleTriggerMessage(ctx context.Context, message kafka.Message, runs chan struct{}, wg *sync.WaitGroup) {
+	var data interface{}
+	if err := json.Unmarshal(message.Value, &data); err != nil {
+		util.Logger.Debug("ignoring trigger message, which is no JSON", "topic", message.Topic, "error", err)
+		return
+	}
+	matches := []bson.M{{"triggers.topic": message.Topic}}
+	if message.Topic == r.Config.Trigger.DeviceLogTopic {
+		matches = append(matches, bson.M{"triggers.type": lib.TriggerTypeDeviceOffline})
+	}
+	cur, err := Reports().Find(CTX, bson.M{"paused": bson.M{"$ne": true}, "$or": matches})
+	if err != nil {
+		util.Logger.Error("could not get triggered reports", "error", err)
+		return
+	}
+	var reports []lib.Report
+	if err = cur.All(CTX, &reports); err != nil {
+		util.Logger.Error("could not decode triggered reports", "error", err)
+		return
+	}
+	for _, report := range reports {
+		if !slices.ContainsFunc(report.Triggers, func(trigger lib.Trigger) bool {
+			return r.matchTrigger(trigger, message, data)
+		}) {
+			continue
+		}
+		claimed, err := r.claimTrigger(report)
+		if err != nil {
+			util.Logger.Error("could not claim trigger of report "+report.Id, "error", err)
+			continue
+		}
+		if !claimed {
+			util.Logger.Debug("skipping triggered run of report " + report.Id + " within debounce interval")
+			continue
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case runs <- struct{}{}:
+		}
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			defer func() { <-runs }()
+			r.executeReport(report, AuditActorTrigger)
+		}()
+	}
+}
+
+// matchTrigger checks if the message of a consumed topic fulfills the trigger.
+func (r *Client) matchTrigger(trigger lib.Trigger, message kafka.Message, data interface{}) bool {
+	switch trigger.Type {
+	case lib.TriggerTypeKafka:
+		if trigger.Topic != message.Topic {
+			return false
+		}
+		for _, rule := range trigger.Filter {
+			if !matchRule(rule, data) {
+				return false
+			}
+		}
+		return true
+	case lib.TriggerTypeDeviceOffline:
+		if message.Topic != r.Config.Trigger.DeviceLogTopic {
+			return false
+		}
+		var log deviceLogMessage
+		if err := json.Unmarshal(message.Value, &log); err != nil || log.Connected == nil || *log.Connected {
+			return false
+		}
+		return slices.Contains(trigger.DeviceIds, log.Id)
+	default:
+		return false
+	}
+}
+
+// claimTrigger records a triggered run of the report, unless the previous one is more recent than the debounce interval.
+// The update is atomic, so concurrent messages and instances run the report only once per interval.
+func (r *Client) claimTrigger(report lib.Report) (bool, error) {
+	interval := r.Config.Trigger.Interval
+	if debounce, err := time.ParseDuration(report.Debounce); err == nil && debounce > interval {
+		interval = debounce
+	}
+	now := time.Now()
+	res, err := Reports().UpdateOne(CTX, bson.M{
+		"_id": report.Id,
+		"$or": []bson.M{{"triggeredat": nil}, {"triggeredat": bson.M{"$lte": now.Add(-interval)}}},
+	}, bson.M{"$set": bson.M{"triggeredat": now}})
+	if err != nil {
+		return false, err
+	}
+	return res.MatchedCount == 1, nil
+}
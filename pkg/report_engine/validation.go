@@ -0,0 +1,105 @@
+/*
+ * Copyright 2025 InfAI (CC SES)
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package report_engine
+
+import (
+	"fmt"
+	"slices"
+
+	"github.com/SENERGY-Platform/reporting-service/lib"
+	"github.com/google/uuid"
+)
+
+// validateReport validates the delivery settings of a report before it is saved and fills generated values.
+// Values omitted by the client, like webhook secrets, are taken from the stored report old.
+func (r *Client) validateReport(report *lib.Report, old lib.Report) (err error) {
+	err = prepareRecipients(report)
+	if err != nil {
+		return
+	}
+	err = r.validateEmailTemplates(*report)
+	if err != nil {
+		return
+	}
+	err = prepareConditions(report.Conditions)
+	if err != nil {
+		return
+	}
+	err = prepareOutputs(report.Outputs)
+	if err != nil {
+		return
+	}
+	err = r.prepareTriggers(report, old)
+	if err != nil {
+		return
+	}
+	err = r.prepareWebhooks(report.Webhooks, old.Webhooks)
+	if err != nil {
+		return
+	}
+	return r.prepareTargets(report.Targets, old.Targets)
+}
+
+func (r *Client) prepareWebhooks(webhooks []lib.Webhook, old []lib.Webhook) error {
+	for i := range webhooks {
+		webhook := &webhooks[i]
+		if err := r.validateOutboundUrl("webhook url", webhook.Url); err != nil {
+			return err
+		}
+		if webhook.Mode == "" {
+			webhook.Mode = lib.WebhookModeNotification
+		}
+		if webhook.Mode != lib.WebhookModeNotification && webhook.Mode != lib.WebhookModeFile {
+			return fmt.Errorf("%w: invalid webhook mode %q", ErrValidation, webhook.Mode)
+		}
+		if webhook.Id == "" {
+			webhook.Id = uuid.New().String()
+		}
+		if webhook.Secret == "" {
+			index := slices.IndexFunc(old, func(o lib.Webhook) bool { return o.Id == webhook.Id })
+			if index < 0 {
+				return fmt.Errorf("%w: webhook requires secret", ErrValidation)
+			}
+			webhook.Secret = old[index].Secret
+		}
+	}
+	return nil
+}
+
+// withoutSecrets returns a copy of the report without webhook secrets and S3 secret keys.
+// Secrets are write-only, every report leaving the service has to be passed through this function.
+func withoutSecrets(report lib.Report) lib.Report {
+	if report.Webhooks != nil {
+		webhooks := slices.Clone(report.Webhooks)
+		for i := range webhooks {
+			webhooks[i].Secret = ""
+		}
+		report.Webhooks = webhooks
+	}
+	if report.Targets != nil {
+		targets := slices.Clone(report.Targets)
+		for i := range targets {
+			if targets[i].S3 != nil {
+				s3 := *targets[i].S3
+				s3.SecretKey = ""
+				targets[i].S3 = &s3
+			}
+		}
+		report.Targets = targets
+	}
+	return report
+}
@@ -0,0 +1,234 @@
+/*
+ * Copyright 2025 InfAI (CC SES)
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package report_engine
+
+import (
+	"fmt"
+	"slices"
+	"time"
+
+	"github.com/SENERGY-Platform/reporting-service/lib"
+	"github.com/SENERGY-Platform/reporting-service/pkg/util"
+	"github.com/SENERGY-Platform/service-commons/pkg/jwt"
+	"github.com/globalsign/mgo/bson"
+	"github.com/google/uuid"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// GetReportVersions lists all saved versions of a report definition, latest first. Requires the read permission.
+func (r *Client) GetReportVersions(reportId string, authTokenString string) (versions []lib.ReportVersion, err error) {
+	claims, err := jwt.Parse(authTokenString)
+	if err != nil {
+		return
+	}
+	_, err = r.getReportModel(reportId, claims, PermissionRead)
+	if err != nil {
+		return
+	}
+	cur, err := ReportVersions().Find(CTX, bson.M{"reportid": reportId}, options.Find().SetSort(bson.M{"version": -1}))
+	if err != nil {
+		return
+	}
+	versions = []lib.ReportVersion{}
+	err = cur.All(CTX, &versions)
+	return
+}
+
+// GetReportVersion retrieves a saved version of a report definition. Requires the read permission.
+func (r *Client) GetReportVersion(reportId string, version int, authTokenString string) (reportVersion lib.ReportVersion, err error) {
+	claims, err := jwt.Parse(authTokenString)
+	if err != nil {
+		return
+	}
+	_, err = r.getReportModel(reportId, claims, PermissionRead)
+	if err != nil {
+		return
+	}
+	return findReportVersion(reportId, version)
+}
+
+// DiffReportVersions compares two saved versions of a report definition. Requires the read permission.
+//
+// Parameters:
+// - reportId: The ID of the report.
+// - from: The version to compare from.
+// - to: The version to compare to.
+// - authTokenString: The authentication token string.
+//
+// Returns:
+// - changes: All changed top level fields of the definition.
+// - err: An error if the operation fails.
+func (r *Client) DiffReportVersions(reportId string, from int, to int, authTokenString string) (changes map[string]lib.AuditChange, err error) {
+	claims, err := jwt.Parse(authTokenString)
+	if err != nil {
+		return
+	}
+	_, err = r.getReportModel(reportId, claims, PermissionRead)
+	if err != nil {
+		return
+	}
+	fromVersion, err := findReportVersion(reportId, from)
+	if err != nil {
+		return
+	}
+	toVersion, err := findReportVersion(reportId, to)
+	if err != nil {
+		return
+	}
+	return AuditChanges(fromVersion.Definition, toVersion.Definition), nil
+}
+
+// RestoreReportVersion replaces the definition of a report with a saved version. The restore is saved as a new version,
+// report files, owner and permissions are kept. Requires the administrate permission.
+// Versions do not contain secrets, so webhooks and S3 targets, which have been removed from the report since, are not
+// restored. They have to be added again with their secrets.
+//
+// Parameters:
+// - reportId: The ID of the report.
+// - version: The version to restore.
+// - authTokenString: The authentication token string.
+//
+// Returns:
+// - report: The restored report.
+// - err: An error if the operation fails.
+func (r *Client) RestoreReportVersion(reportId string, version int, authTokenString string) (report lib.Report, err error) {
+	claims, err := jwt.Parse(authTokenString)
+	if err != nil {
+		return
+	}
+	report, err = r.getReportModel(reportId, claims, PermissionAdministrate)
+	if err != nil {
+		return
+	}
+	reportVersion, err := findReportVersion(reportId, version)
+	if err != nil {
+		return
+	}
+	definition := reportVersion.Definition
+	report.Name = definition.Name
+	report.TemplateName = definition.TemplateName
+	report.TemplateId = definition.TemplateId
+	report.Data = definition.Data
+	report.Cron = definition.Cron
+	report.EmailReceivers = definition.EmailReceivers
+	report.EmailSubject = definition.EmailSubject
+	report.EmailText = definition.EmailText
+	report.EmailHTML = definition.EmailHTML
+	report.EmailZip = definition.EmailZip
+	report.Webhooks = restorableWebhooks(definition.Webhooks, report.Webhooks)
+	report.Targets = restorableTargets(definition.Targets, report.Targets)
+	report.Recipients = definition.Recipients
+	report.ReplyTo = definition.ReplyTo
+	report.Conditions = definition.Conditions
+	report.Outputs = definition.Outputs
+	report.Triggers = definition.Triggers
+	report.Debounce = definition.Debounce
+	err = r.updateReportModel(report, claims, PermissionAdministrate)
+	if err != nil {
+		return
+	}
+	report, err = r.getReportModel(reportId, claims, PermissionAdministrate)
+	return withoutSecrets(report), err
+}
+
+// restorableWebhooks returns the versioned webhooks, which still exist in the report, so their secrets can be kept.
+func restorableWebhooks(versioned []lib.Webhook, current []lib.Webhook) (webhooks []lib.Webhook) {
+	for _, webhook := range versioned {
+		if slices.ContainsFunc(current, func(c lib.Webhook) bool { return c.Id == webhook.Id }) {
+			webhooks = append(webhooks, webhook)
+		}
+	}
+	return
+}
+
+// restorableTargets returns the versioned targets without secret, and the S3 targets, which still exist in the report.
+func restorableTargets(versioned []lib.DeliveryTarget, current []lib.DeliveryTarget) (targets []lib.DeliveryTarget) {
+	for _, target := range versioned {
+		if target.S3 == nil || slices.ContainsFunc(current, func(c lib.DeliveryTarget) bool { return c.Id == target.Id && c.S3 != nil }) {
+			targets = append(targets, target)
+		}
+	}
+	return
+}
+
+// reportDefinition strips everything from a report, which is not defined by the user.
+func reportDefinition(report lib.Report) lib.Report {
+	definition := lib.Report{
+		Name:           report.Name,
+		TemplateName:   report.TemplateName,
+		TemplateId:     report.TemplateId,
+		Data:           report.Data,
+		Cron:           report.Cron,
+		EmailReceivers: report.EmailReceivers,
+		EmailSubject:   report.EmailSubject,
+		EmailText:      report.EmailText,
+		EmailHTML:      report.EmailHTML,
+		EmailZip:       report.EmailZip,
+		Webhooks:       report.Webhooks,
+		Targets:        report.Targets,
+		Recipients:     report.Recipients,
+		ReplyTo:        report.ReplyTo,
+		Conditions:     report.Conditions,
+		Outputs:        report.Outputs,
+		Triggers:       report.Triggers,
+		Debounce:       report.Debounce,
+	}
+	if len(definition.EmailReceivers) == 0 {
+		definition.EmailReceivers = nil
+	}
+	return definition
+}
+
+// nextVersion returns the version of report, when it replaces old, and whether its definition has changed.
+// Secrets are not versioned, changing them does not create a version.
+func nextVersion(old lib.Report, report lib.Report) (version int, changed bool) {
+	if len(AuditChanges(withoutSecrets(reportDefinition(old)), withoutSecrets(reportDefinition(report)))) == 0 {
+		return max(old.Version, 1), false
+	}
+	return max(old.Version, 1) + 1, true
+}
+
+// saveReportVersion stores the definition of the report as its current version. It has to be called before the report
+// is written, so the unique index reserves the version number.
+// Returns ErrConflict, if the version has already been stored by a concurrent update.
+func saveReportVersion(report lib.Report, userId string) (err error) {
+	_, err = ReportVersions().InsertOne(CTX, lib.ReportVersion{
+		Id:         uuid.New().String(),
+		ReportId:   report.Id,
+		Version:    report.Version,
+		Definition: withoutSecrets(reportDefinition(report)),
+		CreatedBy:  userId,
+		CreatedAt:  time.Now(),
+	})
+	if mongo.IsDuplicateKeyError(err) {
+		err = fmt.Errorf("%w: version %d of report %s already exists", ErrConflict, report.Version, report.Id)
+	}
+	return
+}
+
+// deleteReportVersion removes a version saved for a report write, which has failed.
+func deleteReportVersion(reportId string, version int) {
+	if _, err := ReportVersions().DeleteOne(CTX, bson.M{"reportid": reportId, "version": version}); err != nil {
+		util.Logger.Error("could not delete version of report "+reportId, "error", err)
+	}
+}
+
+func findReportVersion(reportId string, version int) (reportVersion lib.ReportVersion, err error) {
+	err = ReportVersions().FindOne(CTX, bson.M{"reportid": reportId, "version": version}).Decode(&reportVersion)
+	return
+}